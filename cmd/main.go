@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,6 +14,19 @@ func main() {
 
 	application.SetConfig()
 
+	if len(os.Args) > 2 {
+		err := application.Storage.Migrate()
+		if err == nil {
+			err = application.RunCommand(os.Args[2], os.Args[3:])
+		}
+		application.Storage.Stop()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	go application.Run()
 
 	stop := make(chan os.Signal, 1)
@@ -2,12 +2,22 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"test-case/internal/config"
+	middleware_auth "test-case/internal/server/middlewares/auth"
+	middleware_ratelimit "test-case/internal/server/middlewares/ratelimit"
+	middleware_rbac "test-case/internal/server/middlewares/rbac"
 	"test-case/internal/server/router"
+	"test-case/internal/utils/details"
+	"test-case/internal/utils/explicit"
+	"test-case/internal/utils/health"
 	"test-case/internal/utils/logger"
+	"test-case/internal/utils/textnorm"
+	"test-case/internal/utils/tracing"
 	"test-case/storage/postgres"
 	"test-case/storage/repos"
 	"time"
@@ -16,18 +26,30 @@ import (
 )
 
 type App struct {
-	Cfg      config.Config
-	Storage  *postgres.Database
-	SongRepo repos.SongRepository
-	Router   *gin.Engine
-	Server   *http.Server
+	Cfg          config.Config
+	Storage      *postgres.Database
+	SongRepo     repos.SongRepository
+	GroupRepo    repos.GroupRepository
+	LyricsRepo   repos.LyricsRepository
+	RevisionRepo repos.RevisionRepository
+	ScanRepo     repos.ScanRepository
+	TrashRepo    repos.TrashRepository
+	AuditRepo    repos.AuditRepository
+	Details      *details.Client
+	Health       *health.Checker
+	Router       *gin.Engine
+	Server       *http.Server
+
+	stopJobs    context.CancelFunc
+	stopTracing func(context.Context) error
 }
 
 func (app *App) readConfig() {
 	args := os.Args[1:]
 
 	if len(args) < 1 {
-		fmt.Println("Usage go run <path to main.go> [arguments] \n Required arguments: \n - Path to config file")
+		fmt.Println("Usage go run <path to main.go> [arguments] \n Required arguments: \n - Path to config file" +
+			"\n Optional arguments: \n - Command to run instead of the server, see app.RunCommand")
 		os.Exit(1)
 	}
 
@@ -37,6 +59,58 @@ func (app *App) readConfig() {
 func (app *App) SetConfig() {
 	app.readConfig()
 
+	// Secrets of the config are masked wherever they would be logged.
+	secrets := []string{app.Cfg.Storage.Password, app.Cfg.Auth.JWTSecret}
+	if err := logger.InitLogger(app.Cfg.Env, app.Cfg.Logging, secrets...); err != nil {
+		fmt.Println("Invalid log settings:", err)
+		os.Exit(1)
+	}
+
+	if err := explicit.Configure(app.Cfg.ExplicitWordlistsDir); err != nil {
+		fmt.Println("Failed to load explicit word lists:", err)
+		os.Exit(1)
+	}
+
+	if err := textnorm.Configure(app.Cfg.NormalizeSteps); err != nil {
+		fmt.Println("Invalid lyrics normalization steps:", err)
+		os.Exit(1)
+	}
+
+	stopTracing, err := tracing.Setup(context.Background(), app.Cfg.Tracing)
+	if err != nil {
+		fmt.Println("Invalid tracing settings:", err)
+		os.Exit(1)
+	}
+	app.stopTracing = stopTracing
+
+	authenticator, err := middleware_auth.New(app.Cfg.Auth)
+	if err != nil {
+		fmt.Println("Invalid authentication settings:", err)
+		os.Exit(1)
+	}
+	if authenticator != nil {
+		for _, role := range authenticator.APIKeyRoles() {
+			if _, err := middleware_rbac.ParseRole(role); err != nil {
+				fmt.Println("Invalid authentication settings:", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	limits := make(map[string]middleware_ratelimit.Limit)
+	for group, spec := range map[string]string{
+		middleware_ratelimit.GroupRead:  app.Cfg.RateLimit.Read,
+		middleware_ratelimit.GroupList:  app.Cfg.RateLimit.List,
+		middleware_ratelimit.GroupWrite: app.Cfg.RateLimit.Write,
+		middleware_ratelimit.GroupIP:    app.Cfg.RateLimit.IP,
+	} {
+		if limits[group], err = middleware_ratelimit.ParseLimit(spec); err != nil {
+			fmt.Println("Invalid rate limits:", err)
+			os.Exit(1)
+		}
+	}
+	limiter := middleware_ratelimit.New(middleware_ratelimit.NewMemoryStore(), limits)
+
 	storage, err := postgres.New(app.Cfg)
 	if err != nil {
 
@@ -45,8 +119,52 @@ func (app *App) SetConfig() {
 	app.Storage = storage
 
 	app.SongRepo = repos.NewSongRepository(app.Storage.Database)
+	app.GroupRepo = repos.NewGroupRepository(app.Storage.Database)
+	app.LyricsRepo = repos.NewLyricsRepository(app.Storage.Database)
+	app.RevisionRepo = repos.NewRevisionRepository(app.Storage.Database)
+	app.ScanRepo = repos.NewScanRepository(app.Storage.Database)
+	app.TrashRepo = repos.NewTrashRepository(app.Storage.Database)
+	app.AuditRepo = repos.NewAuditRepository(app.Storage.Database)
+
+	app.Details, err = details.New(app.Cfg.Details.BaseURL, app.Cfg.Details.Timeout)
+	if err != nil {
+		fmt.Println("Invalid details provider URL:", err)
+		os.Exit(1)
+	}
 
-	app.Router = router.SetupRouter(app.SongRepo)
+	checks := map[string]health.Check{
+		"database": app.Storage.Ping,
+		"migrations": func(context.Context) error {
+			if !app.Storage.Migrated() {
+				return errors.New("migrations are still running")
+			}
+			return nil
+		},
+		"details": app.Details.Ping,
+	}
+	names, err := health.Names(app.Cfg.Health.Checks, []string{"database", "migrations", "details"})
+	if err != nil {
+		fmt.Println("Invalid readiness checks:", err)
+		os.Exit(1)
+	}
+	app.Health = health.New(app.Cfg.Health.Timeout)
+	for _, name := range names {
+		app.Health.Add(name, checks[name])
+	}
+
+	app.Router = router.SetupRouter(app.SongRepo, app.GroupRepo, app.LyricsRepo, app.RevisionRepo, app.TrashRepo, app.AuditRepo,
+		authenticator, limiter, app.Details, app.Health)
+
+	var proxies []string
+	for _, proxy := range strings.Split(app.Cfg.HttpServer.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := app.Router.SetTrustedProxies(proxies); err != nil {
+		fmt.Println("Invalid trusted proxies:", err)
+		os.Exit(1)
+	}
 
 	app.Server = &http.Server{
 		Addr:    app.Cfg.Address,
@@ -57,7 +175,20 @@ func (app *App) SetConfig() {
 func (app *App) Run() {
 	const op = "app.Run"
 
-	logger.InitLogger(app.Cfg.Env)
+	ctx, cancel := context.WithCancel(context.Background())
+	app.stopJobs = cancel
+
+	// The server answers liveness probes while migrations run, /readyz
+	// fails until they are done.
+	go func() {
+		if err := app.Storage.Migrate(); err != nil {
+			logger.Logger.Fatal().Msg(fmt.Sprint("Fatal error", op, err.Error()))
+			return
+		}
+		logger.Logger.Info().Msg("Migrations applied")
+
+		app.purgeTrash(ctx)
+	}()
 
 	logger.Logger.Info().Str("address:", app.Server.Addr).Msg("Server started")
 	if err := app.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -69,12 +200,29 @@ func (app *App) Run() {
 func (app *App) Stop() {
 	const op = "app.Stop"
 
+	app.Health.Shutdown()
+	// Requests keep being served while the failing /readyz takes the
+	// instance out of rotation.
+	if app.Cfg.Health.ShutdownDelay > 0 {
+		logger.Logger.Info().Dur("delay", app.Cfg.Health.ShutdownDelay).Msg("Draining before shutdown")
+		time.Sleep(app.Cfg.Health.ShutdownDelay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	app.Storage.Stop()
+	if app.stopJobs != nil {
+		app.stopJobs()
+	}
 
-	if err := app.Server.Shutdown(ctx); err != nil {
+	err := app.Server.Shutdown(ctx)
+	app.Storage.Stop()
+	if app.stopTracing != nil {
+		if err := app.stopTracing(ctx); err != nil {
+			logger.Logger.Warn().Msg(fmt.Sprint("Spans lost on shutdown", op, err.Error()))
+		}
+	}
+	if err != nil {
 		logger.Logger.Warn().Msg(fmt.Sprint("Server forced to shutdown", op, err.Error()))
 		return
 	}
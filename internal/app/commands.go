@@ -0,0 +1,230 @@
+package app
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"test-case/internal/models"
+	"test-case/internal/utils/requestinfo"
+	"test-case/internal/utils/songexport"
+	"test-case/internal/utils/songimport"
+	"test-case/storage/repos"
+)
+
+// RunCommand runs a one-off maintenance command against the configured
+// storage instead of starting the server.
+//
+// Commands:
+//   - backfill-language [-all]: detect the lyrics language of stored songs
+//   - backfill-explicit: flag explicit lyrics of stored songs
+//   - normalize-text [-dry-run]: clean up the lyrics of stored songs
+//   - import [-format csv|json|ndjson] [-dry-run] [-resume N] <file>: import songs in bulk
+//   - export [-format csv|json|ndjson|xlsx] [-gzip] [-filter key=value]... <file>: export songs
+//   - scan <directory>: add songs from the tags of MP3, FLAC and OGG files
+func (app *App) RunCommand(name string, args []string) error {
+	switch name {
+	case "backfill-language":
+		return app.backfillLanguage(args)
+	case "backfill-explicit":
+		return app.backfillExplicit()
+	case "normalize-text":
+		return app.normalizeText(args)
+	case "import":
+		return app.importSongs(args)
+	case "export":
+		return app.exportSongs(args)
+	case "scan":
+		return app.scan(args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+func (app *App) backfillLanguage(args []string) error {
+	flags := flag.NewFlagSet("backfill-language", flag.ContinueOnError)
+	all := flags.Bool("all", false, "re-detect songs that already have a language")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := requestinfo.WithInfo(context.Background(), requestinfo.Info{Author: "backfill-language"})
+
+	processed, err := app.SongRepo.DetectLanguages(ctx, *all)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Language detected for %d songs\n", processed)
+
+	return nil
+}
+
+func (app *App) backfillExplicit() error {
+	ctx := requestinfo.WithInfo(context.Background(), requestinfo.Info{Author: "backfill-explicit"})
+
+	processed, err := app.SongRepo.FlagExplicit(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Explicit content checked for %d songs\n", processed)
+
+	return nil
+}
+
+func (app *App) normalizeText(args []string) error {
+	flags := flag.NewFlagSet("normalize-text", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only count the songs that would change")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := requestinfo.WithInfo(context.Background(), requestinfo.Info{Author: "normalize-text"})
+
+	changed, err := app.SongRepo.NormalizeTexts(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Printf("Text of %d songs would be normalized\n", changed)
+		return nil
+	}
+
+	fmt.Printf("Text of %d songs normalized\n", changed)
+
+	return nil
+}
+
+func (app *App) importSongs(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := flags.String("format", "", "csv, json or ndjson, guessed from the file extension when empty")
+	dryRun := flags.Bool("dry-run", false, "validate the file without storing anything")
+	resume := flags.Int("resume", 0, "skip rows up to and including this row")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("import needs exactly one file")
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		*format = songimport.DetectFormat(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := songimport.NewReader(file, *format)
+	if err != nil {
+		return err
+	}
+
+	ctx := requestinfo.WithInfo(context.Background(), requestinfo.Info{Author: "import", Reason: path})
+
+	report, err := app.SongRepo.ImportSongs(ctx, reader, repos.ImportOptions{DryRun: *dryRun, Resume: *resume})
+	for _, rowErr := range report.Errors {
+		fmt.Printf("row %d: %s\n", rowErr.Row, rowErr.Error)
+	}
+	if report.DryRun {
+		fmt.Printf("Read %d rows: %d would be imported, %d skipped, %d failed, last row %d\n",
+			report.Read, report.WouldImport, report.Skipped, report.Failed, report.LastRow)
+	} else {
+		fmt.Printf("Read %d rows: %d imported, %d skipped, %d failed, last row %d\n",
+			report.Read, report.Imported, report.Skipped, report.Failed, report.LastRow)
+	}
+	if len(report.Errors) < report.Failed {
+		fmt.Printf("%d more row errors not listed\n", report.Failed-len(report.Errors))
+	}
+	if err != nil {
+		return fmt.Errorf("import stopped, continue with -resume %d: %w", report.LastRow, err)
+	}
+
+	return nil
+}
+
+func (app *App) exportSongs(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "", "csv, json, ndjson or xlsx, guessed from the file extension when empty")
+	compress := flags.Bool("gzip", false, "compress the file, implied by a .gz extension")
+	filterParams := make(map[string]string)
+	flags.Func("filter", "get-songs filter as key=value, may be repeated", func(value string) error {
+		key, filter, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("filter %q is not key=value", value)
+		}
+		filterParams[key] = filter
+		return nil
+	})
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("export needs exactly one file")
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		*format = songexport.DetectFormat(path)
+	}
+	if strings.HasSuffix(path, ".gz") {
+		*compress = true
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffer := bufio.NewWriter(file)
+
+	var dst io.Writer = buffer
+	var compressor *gzip.Writer
+	if *compress {
+		compressor = gzip.NewWriter(buffer)
+		dst = compressor
+	}
+
+	writer, err := songexport.NewWriter(dst, *format)
+	if err != nil {
+		return err
+	}
+
+	exported := 0
+	err = app.SongRepo.ExportSongs(context.Background(), filterParams, func(song models.Song) error {
+		exported++
+		return writer.Write(songexport.NewRecord(song))
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return err
+		}
+	}
+	if err := buffer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d songs to %s\n", exported, path)
+
+	return file.Close()
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/requestinfo"
+	"time"
+)
+
+// purgeInterval is how often the trash is checked for expired items.
+const purgeInterval = time.Hour
+
+// purgeTrash deletes songs and groups that stayed in the trash longer
+// than the configured retention, until ctx is cancelled.
+func (app *App) purgeTrash(ctx context.Context) {
+	const op = "app.purgeTrash"
+
+	if app.Cfg.Retention <= 0 {
+		return
+	}
+
+	ctx = requestinfo.WithInfo(ctx, requestinfo.Info{Author: "trash-retention"})
+
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := app.TrashRepo.Purge(ctx, time.Now().Add(-app.Cfg.Retention)); err != nil {
+			logger.Logger.Warn().Interface("Error occured: ", err).Msg(op)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
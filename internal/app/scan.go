@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"test-case/internal/models"
+	"test-case/internal/utils/audiotags"
+	"test-case/internal/utils/requestinfo"
+	"test-case/storage/repos"
+	"time"
+)
+
+var audioExtensions = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+}
+
+type scanSummary struct {
+	files      int
+	known      int
+	added      int
+	duplicates int
+	noTags     int
+	failed     int
+}
+
+// scan walks a music folder and adds a song for every tagged audio file
+// it has not seen before.
+func (app *App) scan(args []string) error {
+	if len(args) != 1 {
+		return errors.New("scan needs exactly one directory")
+	}
+
+	root, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	ctx := requestinfo.WithInfo(context.Background(), requestinfo.Info{Author: "scan", Reason: root})
+
+	var summary scanSummary
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			fmt.Printf("%s: %s\n", path, err)
+			summary.failed++
+			return nil
+		}
+
+		if entry.IsDir() || !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		summary.files++
+
+		file, err := app.scanFile(ctx, path)
+		if err != nil {
+			return err
+		}
+
+		switch file.Status {
+		case "":
+			summary.known++
+		case models.ScanAdded:
+			summary.added++
+		case models.ScanDuplicate:
+			summary.duplicates++
+		case models.ScanNoTags:
+			summary.noTags++
+			fmt.Printf("%s: %s\n", path, file.Status)
+		case models.ScanFailed:
+			summary.failed++
+			fmt.Printf("%s: %s\n", path, file.Error)
+		}
+
+		return nil
+	})
+
+	fmt.Printf("Scanned %d audio files: %d added, %d already known, %d duplicates, %d without tags, %d failed\n",
+		summary.files, summary.added, summary.known, summary.duplicates, summary.noTags, summary.failed)
+
+	return err
+}
+
+// scanFile reads and records a single file. Problems with the file end up
+// in its status, only storage errors are returned. Known files come back
+// with an empty status.
+func (app *App) scanFile(ctx context.Context, path string) (models.ScannedFile, error) {
+	record := models.ScannedFile{Path: path, ScannedAt: time.Now()}
+
+	tags, err := readTags(&record)
+	if err != nil {
+		record.Status = models.ScanFailed
+		record.Error = err.Error()
+		if record.Hash == "" {
+			// Without a hash the file cannot be told apart later.
+			return record, nil
+		}
+		return record, app.ScanRepo.RecordFile(ctx, record)
+	}
+
+	known, err := app.ScanRepo.IsKnown(ctx, path, record.Hash)
+	if err != nil || known {
+		return models.ScannedFile{}, err
+	}
+
+	if tags == nil || tags.Artist == "" || tags.Title == "" {
+		record.Status = models.ScanNoTags
+		return record, app.ScanRepo.RecordFile(ctx, record)
+	}
+
+	record.Artist = tags.Artist
+	record.Title = tags.Title
+	record.Album = tags.Album
+
+	id, err := app.SongRepo.AddSong(ctx, models.Song{
+		Band:        tags.Artist,
+		Song:        tags.Title,
+		ReleaseDate: tags.ReleaseDate(),
+		Text:        tags.Lyrics,
+	})
+	switch {
+	case errors.Is(err, repos.ErrDuplicateSong):
+		record.Status = models.ScanDuplicate
+	case err != nil:
+		return record, err
+	default:
+		record.Status = models.ScanAdded
+		record.SongId = &id
+	}
+
+	return record, app.ScanRepo.RecordFile(ctx, record)
+}
+
+// readTags hashes the file and reads its tags. Files without usable tags
+// come back with nil tags and no error.
+func readTags(record *models.ScannedFile) (*audiotags.Tags, error) {
+	file, err := os.Open(record.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, err
+	}
+	record.Hash = hex.EncodeToString(hash.Sum(nil))
+	record.Size = size
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	tags, err := audiotags.Read(file)
+	if errors.Is(err, audiotags.ErrNoTags) || errors.Is(err, audiotags.ErrUnsupported) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &tags, nil
+}
@@ -4,14 +4,35 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	Env string
+	Logging
 	Storage
 	HttpServer
+	Lyrics
+	Trash
+	Auth
+	RateLimit
+	Details
+	Health
+	Tracing
+}
+
+type Logging struct {
+	// Level is the global level, empty picks debug locally and info
+	// elsewhere.
+	Level string
+	// Packages overrides the level of single packages, as a comma
+	// separated list of package=level, like storage.repos=debug.
+	Packages string
+	// MaxFieldLength is how many characters of a string field, as song
+	// text, make it into the log.
+	MaxFieldLength int
 }
 
 type Storage struct {
@@ -24,6 +45,64 @@ type Storage struct {
 
 type HttpServer struct {
 	Address string
+	// TrustedProxies lists the proxy IPs and CIDRs, comma separated,
+	// whose X-Forwarded-For gives the client IP. Empty trusts none.
+	TrustedProxies string
+}
+
+type Trash struct {
+	// Retention is how long deleted songs and groups stay restorable,
+	// zero keeps them until they are purged by hand.
+	Retention time.Duration
+}
+
+type Auth struct {
+	// Disabled leaves every endpoint open, for local development only.
+	Disabled bool
+	// APIKeys lists name:role:sha256-hex entries, comma separated. Only
+	// the hashes of the keys are kept in the config.
+	APIKeys   string
+	JWTSecret string
+	JWKSFile  string
+	Issuer    string
+	Audience  string
+}
+
+// RateLimit holds the requests/period limits of the route groups, see
+// middleware_ratelimit.ParseLimit. Empty means no limit.
+type RateLimit struct {
+	Read  string
+	List  string
+	Write string
+	IP    string
+}
+
+type Details struct {
+	BaseURL string
+	Timeout time.Duration
+}
+
+type Health struct {
+	// Checks lists the readiness checks to run, comma separated:
+	// database, migrations, details. Empty runs all of them.
+	Checks  string
+	Timeout time.Duration
+	// ShutdownDelay is how long /readyz fails before the server stops
+	// accepting connections, so the balancer takes it out of rotation.
+	ShutdownDelay time.Duration
+}
+
+type Tracing struct {
+	// Exporter is otlp, stdout or none. The OTLP endpoint is set by the
+	// standard OTEL_EXPORTER_OTLP_ENDPOINT variable.
+	Exporter    string
+	ServiceName string
+	SampleRatio float64
+}
+
+type Lyrics struct {
+	ExplicitWordlistsDir string
+	NormalizeSteps       string
 }
 
 func ReadConfig(configPath string) Config {
@@ -50,7 +129,84 @@ func ReadConfig(configPath string) Config {
 	cfg.Storage.Password = os.Getenv("DB_PASSWORD")
 	cfg.Storage.DbName = os.Getenv("DB_NAME")
 
+	cfg.Logging.Level = os.Getenv("LOG_LEVEL")
+	cfg.Logging.Packages = os.Getenv("LOG_LEVEL_PACKAGES")
+	cfg.Logging.MaxFieldLength = 200
+	if length := os.Getenv("LOG_MAX_FIELD_LENGTH"); length != "" {
+		cfg.Logging.MaxFieldLength, err = strconv.Atoi(length)
+		if err != nil {
+			log.Fatal("invalid .env file ", err.Error())
+		}
+	}
+
 	cfg.HttpServer.Address = os.Getenv("ADDR")
+	cfg.HttpServer.TrustedProxies = os.Getenv("TRUSTED_PROXIES")
+
+	cfg.Lyrics.ExplicitWordlistsDir = os.Getenv("EXPLICIT_WORDLISTS_DIR")
+	cfg.Lyrics.NormalizeSteps = os.Getenv("LYRICS_NORMALIZE")
+
+	cfg.Trash.Retention = 30 * 24 * time.Hour
+	if retention := os.Getenv("TRASH_RETENTION"); retention != "" {
+		cfg.Trash.Retention, err = time.ParseDuration(retention)
+		if err != nil {
+			log.Fatal("invalid .env file ", err.Error())
+		}
+	}
+
+	if disabled := os.Getenv("AUTH_DISABLED"); disabled != "" {
+		cfg.Auth.Disabled, err = strconv.ParseBool(disabled)
+		if err != nil {
+			log.Fatal("invalid .env file ", err.Error())
+		}
+	}
+	cfg.Auth.APIKeys = os.Getenv("AUTH_API_KEYS")
+	cfg.Auth.JWTSecret = os.Getenv("JWT_SECRET")
+	cfg.Auth.JWKSFile = os.Getenv("JWT_JWKS_FILE")
+	cfg.Auth.Issuer = os.Getenv("JWT_ISSUER")
+	cfg.Auth.Audience = os.Getenv("JWT_AUDIENCE")
+
+	cfg.Details.BaseURL = os.Getenv("BASE_URL")
+	cfg.Details.Timeout = 10 * time.Second
+	if timeout := os.Getenv("DETAILS_TIMEOUT"); timeout != "" {
+		cfg.Details.Timeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			log.Fatal("invalid .env file ", err.Error())
+		}
+	}
+
+	cfg.Health.Checks = os.Getenv("READINESS_CHECKS")
+	cfg.Health.Timeout = 2 * time.Second
+	if timeout := os.Getenv("READINESS_TIMEOUT"); timeout != "" {
+		cfg.Health.Timeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			log.Fatal("invalid .env file ", err.Error())
+		}
+	}
+	cfg.Health.ShutdownDelay = 5 * time.Second
+	if delay := os.Getenv("SHUTDOWN_DELAY"); delay != "" {
+		cfg.Health.ShutdownDelay, err = time.ParseDuration(delay)
+		if err != nil {
+			log.Fatal("invalid .env file ", err.Error())
+		}
+	}
+
+	cfg.Tracing.Exporter = os.Getenv("TRACING_EXPORTER")
+	cfg.Tracing.ServiceName = os.Getenv("OTEL_SERVICE_NAME")
+	if cfg.Tracing.ServiceName == "" {
+		cfg.Tracing.ServiceName = "song-library"
+	}
+	cfg.Tracing.SampleRatio = 1
+	if ratio := os.Getenv("TRACING_SAMPLE_RATIO"); ratio != "" {
+		cfg.Tracing.SampleRatio, err = strconv.ParseFloat(ratio, 64)
+		if err != nil {
+			log.Fatal("invalid .env file ", err.Error())
+		}
+	}
+
+	cfg.RateLimit.Read = os.Getenv("RATE_LIMIT_READ")
+	cfg.RateLimit.List = os.Getenv("RATE_LIMIT_LIST")
+	cfg.RateLimit.Write = os.Getenv("RATE_LIMIT_WRITE")
+	cfg.RateLimit.IP = os.Getenv("RATE_LIMIT_IP")
 
 	return cfg
 }
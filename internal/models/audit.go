@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditMerge   = "merge"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+
+	AuditSong   = "song"
+	AuditGroup  = "group"
+	AuditLyrics = "lyrics"
+)
+
+// AuditEntry records a single write. Entries are only ever inserted and
+// outlive the rows they describe, so they hold no foreign keys.
+type AuditEntry struct {
+	Id        uint            `gorm:"primarykey;autoIncrement" json:"id"`
+	CreatedAt time.Time       `gorm:"column:created_at;index:audit_created_at_index" json:"createdAt"`
+	Actor     string          `gorm:"column:actor;index:audit_actor_index" json:"actor"`
+	Action    string          `gorm:"column:action;notnull" json:"action"`
+	Entity    string          `gorm:"column:entity;index:audit_entity_index,priority:1;notnull" json:"entity"`
+	EntityId  uint            `gorm:"column:entity_id;index:audit_entity_index,priority:2" json:"entityId"`
+	Reason    string          `gorm:"column:reason" json:"reason,omitempty"`
+	RequestId string          `gorm:"column:request_id" json:"requestId,omitempty"`
+	ClientIP  string          `gorm:"column:client_ip" json:"clientIp,omitempty"`
+	Before    json.RawMessage `gorm:"column:before;type:jsonb" json:"before,omitempty"`
+	After     json.RawMessage `gorm:"column:after;type:jsonb" json:"after,omitempty"`
+}
+
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type DuplicateCandidate struct {
+	SongId      uint    `json:"songId"`
+	DuplicateId uint    `json:"duplicateId"`
+	Band        string  `json:"band"`
+	Song        string  `json:"song"`
+	Duplicate   string  `json:"duplicate"`
+	Score       float64 `json:"score"`
+}
+
+type SongMerge struct {
+	Id         uint      `gorm:"primarykey;autoIncrement"`
+	KeptId     uint      `gorm:"index:song_merge_kept_index;notnull"`
+	MergedId   uint      `gorm:"notnull"`
+	MergedSong string    `gorm:"column:merged_song"`
+	Score      float64   `gorm:"column:score"`
+	CreatedAt  time.Time `gorm:"column:created_at"`
+}
+
+func (SongMerge) TableName() string {
+	return "song_merges"
+}
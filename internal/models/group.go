@@ -1,11 +1,27 @@
 package models
 
+import (
+	"test-case/internal/utils/normalize"
+
+	"gorm.io/gorm"
+)
+
 type Group struct {
-	Id    uint   `gorm:"primarykey;autoIncrement"`
-	Name  string `gorm:"notnull"`
-	Songs []Song `json:"-"`
+	Id        uint           `gorm:"primarykey;autoIncrement"`
+	Name      string         `gorm:"notnull"`
+	NameKey   string         `gorm:"column:name_key;uniqueIndex:group_name_key_active_index,where:deleted_at IS NULL;notnull" json:"-"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index:group_deleted_at_index"`
+	Songs     []Song         `json:"-"`
 }
 
 func (Group) TableName() string {
 	return "groups"
 }
+
+// BeforeSave keeps the stored name and its lookup key normalized
+// no matter which code path writes the group.
+func (g *Group) BeforeSave(tx *gorm.DB) error {
+	g.Name = normalize.Name(g.Name)
+	g.NameKey = normalize.NameKey(g.Name)
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"test-case/internal/utils/normalize"
+
+	"gorm.io/gorm"
+)
+
+type GroupAlias struct {
+	Id       uint   `gorm:"primarykey;autoIncrement"`
+	GroupId  uint   `gorm:"index:group_alias_group_index;notnull"`
+	Alias    string `gorm:"notnull"`
+	AliasKey string `gorm:"column:alias_key;uniqueIndex:group_alias_key_index;notnull" json:"-"`
+	Group    Group  `json:"-"`
+}
+
+func (GroupAlias) TableName() string {
+	return "group_aliases"
+}
+
+func (a *GroupAlias) BeforeSave(tx *gorm.DB) error {
+	a.Alias = normalize.Name(a.Alias)
+	a.AliasKey = normalize.NameKey(a.Alias)
+	return nil
+}
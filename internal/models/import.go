@@ -0,0 +1,24 @@
+package models
+
+// ImportReport summarizes a bulk import. LastRow is the last row whose
+// batch was committed (or checked, on a dry run); passing it as resume
+// continues an interrupted import after that row. A dry run counts the
+// rows it would store in WouldImport. Errors holds the first 1000 row
+// errors, Failed counts all of them.
+type ImportReport struct {
+	DryRun      bool             `json:"dryRun"`
+	Read        int              `json:"read"`
+	Skipped     int              `json:"skipped"`
+	Imported    int              `json:"imported"`
+	WouldImport int              `json:"wouldImport"`
+	Failed      int              `json:"failed"`
+	LastRow     int              `json:"lastRow"`
+	Errors      []ImportRowError `json:"errors"`
+}
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Band  string `json:"band,omitempty"`
+	Song  string `json:"song,omitempty"`
+	Error string `json:"error"`
+}
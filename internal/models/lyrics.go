@@ -0,0 +1,22 @@
+package models
+
+const (
+	LyricsOriginal        = "original"
+	LyricsTranslation     = "translation"
+	LyricsTransliteration = "transliteration"
+)
+
+// Lyrics holds the text of a song in one language. Every song has one
+// original entry kept in sync with Song.Text; the rest are translations
+// or transliterations of it.
+type Lyrics struct {
+	Id       uint   `gorm:"primarykey;autoIncrement" json:"-"`
+	SongId   uint   `gorm:"uniqueIndex:lyrics_song_language_index;uniqueIndex:lyrics_original_index,where:kind = 'original';notnull"`
+	Language string `gorm:"uniqueIndex:lyrics_song_language_index;notnull"`
+	Kind     string `gorm:"notnull"`
+	Text     string `gorm:"column:text"`
+}
+
+func (Lyrics) TableName() string {
+	return "lyrics"
+}
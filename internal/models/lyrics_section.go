@@ -0,0 +1,15 @@
+package models
+
+type LyricsSection struct {
+	Id       uint   `gorm:"primarykey;autoIncrement"`
+	SongId   uint   `gorm:"index:lyrics_section_song_index;notnull"`
+	Position int    `gorm:"notnull"`
+	Kind     string `gorm:"notnull"`
+	Label    string `gorm:"column:label"`
+	Text     string `gorm:"column:text"`
+	RepeatOf *int   `gorm:"column:repeat_of"`
+}
+
+func (LyricsSection) TableName() string {
+	return "lyrics_sections"
+}
@@ -0,0 +1,19 @@
+package models
+
+import "test-case/internal/utils/lyricstats"
+
+type SongRepetition struct {
+	SongId          uint    `json:"song_id"`
+	Song            string  `json:"song"`
+	RepetitionRatio float64 `json:"repetition_ratio"`
+}
+
+// GroupLyricsStats aggregates the lyrics of every song of a group. Songs
+// are listed from the most to the least repetitive.
+type GroupLyricsStats struct {
+	GroupId uint             `json:"group_id"`
+	Band    string           `json:"band"`
+	Songs   int              `json:"songs"`
+	Stats   lyricstats.Stats `json:"stats"`
+	Ranking []SongRepetition `json:"repetition_ranking"`
+}
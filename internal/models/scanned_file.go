@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	ScanAdded     = "added"
+	ScanDuplicate = "duplicate"
+	ScanNoTags    = "no tags"
+	ScanFailed    = "failed"
+)
+
+// ScannedFile remembers an audio file seen by the scan command, so that
+// later scans skip it while its content stays the same.
+type ScannedFile struct {
+	Id        uint      `gorm:"primarykey;autoIncrement"`
+	Path      string    `gorm:"uniqueIndex:scanned_file_path_index;notnull"`
+	Hash      string    `gorm:"index:scanned_file_hash_index;notnull"`
+	Size      int64     `gorm:"column:size"`
+	SongId    *uint     `gorm:"index:scanned_file_song_index"`
+	Song      *Song     `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	Status    string    `gorm:"column:status"`
+	Error     string    `gorm:"column:error"`
+	Artist    string    `gorm:"column:artist"`
+	Title     string    `gorm:"column:title"`
+	Album     string    `gorm:"column:album"`
+	ScannedAt time.Time `gorm:"column:scanned_at"`
+}
+
+func (ScannedFile) TableName() string {
+	return "scanned_files"
+}
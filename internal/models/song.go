@@ -1,5 +1,7 @@
 package models
 
+import "gorm.io/gorm"
+
 type Song struct {
 	Id          uint   `gorm:"primarykey;autoIncrement"`
 	Band        string `gorm:"-"`
@@ -9,6 +11,20 @@ type Song struct {
 	Link        string `gorm:"index:link_index;column:link"`
 	GroupId     uint   `gorm:"foreignKey:group_id"`
 	Group       Group  `json:"-"`
+
+	Language           string  `gorm:"column:language;index:song_language_index"`
+	LanguageConfidence float64 `gorm:"column:language_confidence"`
+
+	Explicit         bool  `gorm:"column:explicit;index:song_explicit_index;notnull;default:false"`
+	ExplicitLines    []int `gorm:"column:explicit_lines;serializer:json"`
+	ExplicitOverride *bool `gorm:"column:explicit_override"`
+
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index:song_deleted_at_index"`
+
+	Sections    []LyricsSection `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	SyncedLines []SyncedLine    `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Lyrics      []Lyrics        `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Revisions   []SongRevision  `gorm:"constraint:OnDelete:CASCADE" json:"-"`
 }
 
 func (Song) TableName() string {
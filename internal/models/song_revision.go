@@ -0,0 +1,35 @@
+package models
+
+import (
+	"test-case/internal/utils/diff"
+	"time"
+)
+
+// SongRevision is a snapshot of the lyrics and metadata of a song taken
+// after every change, numbered from 1 per song.
+type SongRevision struct {
+	Id          uint      `gorm:"primarykey;autoIncrement" json:"-"`
+	SongId      uint      `gorm:"uniqueIndex:song_revision_number_index;notnull"`
+	Number      int       `gorm:"uniqueIndex:song_revision_number_index;notnull"`
+	Author      string    `gorm:"column:author"`
+	Reason      string    `gorm:"column:reason"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+	Band        string    `gorm:"column:band"`
+	Song        string    `gorm:"column:song"`
+	ReleaseDate string    `gorm:"column:release_date"`
+	Text        string    `gorm:"column:text"`
+	Link        string    `gorm:"column:link"`
+}
+
+func (SongRevision) TableName() string {
+	return "song_revisions"
+}
+
+// RevisionDiff compares two revisions: changed metadata fields as
+// [old, new] pairs and a line-level diff of the lyrics.
+type RevisionDiff struct {
+	From   int                  `json:"from"`
+	To     int                  `json:"to"`
+	Fields map[string][2]string `json:"fields"`
+	Text   []diff.Line          `json:"text"`
+}
@@ -0,0 +1,19 @@
+package models
+
+type SyncedWord struct {
+	Time int64
+	Text string
+}
+
+type SyncedLine struct {
+	Id       uint         `gorm:"primarykey;autoIncrement"`
+	SongId   uint         `gorm:"index:synced_line_song_index;notnull"`
+	Position int          `gorm:"notnull"`
+	Time     int64        `gorm:"column:time_ms;notnull"`
+	Text     string       `gorm:"column:text"`
+	Words    []SyncedWord `gorm:"column:words;serializer:json"`
+}
+
+func (SyncedLine) TableName() string {
+	return "synced_lines"
+}
@@ -0,0 +1,12 @@
+package models
+
+// Trash lists the deleted songs and groups that can still be restored.
+type Trash struct {
+	Groups []Group `json:"groups"`
+	Songs  []Song  `json:"songs"`
+}
+
+type PurgeResult struct {
+	Songs  int64 `json:"songs"`
+	Groups int64 `json:"groups"`
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/storage/repos"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	repo repos.AuditRepository
+}
+
+func NewAuditHandler(repos repos.AuditRepository) AuditHandler {
+	return AuditHandler{repo: repos}
+}
+
+var auditColumns = []string{"id", "createdAt", "actor", "action", "entity", "entityId", "reason", "requestId", "clientIp", "before", "after"}
+
+// GetAudit godoc
+//
+// @Summary Get the audit log
+// @Description Retrieve audit log entries, newest first, with pagination and filtering
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit of entries per page"
+// @Param actor query string false "Filter by actor"
+// @Param action query string false "Filter by action: create, update, delete, merge, restore or purge"
+// @Param entity query string false "Filter by entity: song, group or lyrics"
+// @Param entityId query int false "Filter by entity ID"
+// @Param from query string false "Entries written at or after this RFC 3339 time"
+// @Param to query string false "Entries written before this RFC 3339 time"
+// @Success 200 {array} models.AuditEntry
+// @Failure 400 {object} gin.H
+// @Router /audit [get]
+func (h *AuditHandler) GetAudit(c *gin.Context) {
+	const op = "handlers.GetAudit"
+
+	filterParams := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			filterParams[key] = values[0]
+		}
+	}
+
+	result, err := h.repo.GetAudit(c.Request.Context(), filterParams, c.Query("page"), c.Query("limit"))
+	if err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportAudit godoc
+//
+// @Summary Export the audit log
+// @Description Stream all audit log entries matching the filters, oldest first, as NDJSON or CSV
+// @Tags audit
+// @Produce application/x-ndjson,text/csv
+// @Param format query string false "ndjson (default) or csv"
+// @Param actor query string false "Filter by actor"
+// @Param action query string false "Filter by action"
+// @Param entity query string false "Filter by entity: song, group or lyrics"
+// @Param entityId query int false "Filter by entity ID"
+// @Param from query string false "Entries written at or after this RFC 3339 time"
+// @Param to query string false "Entries written before this RFC 3339 time"
+// @Success 200 {file} file
+// @Failure 400 {object} gin.H
+// @Router /audit/export [get]
+func (h *AuditHandler) ExportAudit(c *gin.Context) {
+	const op = "handlers.ExportAudit"
+
+	filterParams := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 && key != "format" {
+			filterParams[key] = values[0]
+		}
+	}
+
+	// Nothing reaches the client before the buffer fills up, so errors in
+	// the filters can still be answered with a JSON error.
+	buffer := bufio.NewWriterSize(c.Writer, 32*1024)
+
+	var write func(models.AuditEntry) error
+	var finish func() error
+
+	format := c.DefaultQuery("format", "ndjson")
+	switch format {
+	case "ndjson":
+		encoder := json.NewEncoder(buffer)
+		write = func(entry models.AuditEntry) error {
+			return encoder.Encode(entry)
+		}
+		finish = func() error { return nil }
+		c.Header("Content-Type", "application/x-ndjson")
+	case "csv":
+		writer := csv.NewWriter(buffer)
+		if err := writer.Write(auditColumns); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+		write = func(entry models.AuditEntry) error {
+			return writer.Write([]string{
+				strconv.FormatUint(uint64(entry.Id), 10),
+				entry.CreatedAt.Format(time.RFC3339),
+				entry.Actor,
+				entry.Action,
+				entry.Entity,
+				strconv.FormatUint(uint64(entry.EntityId), 10),
+				entry.Reason,
+				entry.RequestId,
+				entry.ClientIP,
+				string(entry.Before),
+				string(entry.After),
+			})
+		}
+		finish = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "unknown export format " + strconv.Quote(format)})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="audit.`+format+`"`)
+
+	err := h.repo.ExportAudit(c.Request.Context(), filterParams, write)
+	if err == nil {
+		err = finish()
+	}
+	if err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+		// The response is already on its way and stays incomplete.
+		c.Abort()
+		return
+	}
+
+	if err := buffer.Flush(); err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"test-case/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const defaultDuplicateThreshold = 0.85
+
+// GetDuplicates godoc
+//
+// @Summary Get duplicate candidates
+// @Description Detect likely duplicate songs by normalized title, band and lyric similarity
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param threshold query number false "Minimal similarity score from 0 to 1"
+// @Success 200 {array} models.DuplicateCandidate
+// @Failure 400 {object} gin.H
+// @Router /get-duplicates [get]
+func (h *SongHandler) GetDuplicates(c *gin.Context) {
+	const op = "handlers.GetDuplicates"
+
+	threshold := defaultDuplicateThreshold
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": "threshold must be a number from 0 to 1"})
+			return
+		}
+		threshold = parsed
+	}
+
+	result, err := h.repo.FindDuplicates(c.Request.Context(), threshold)
+	if err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// MergeSongs godoc
+//
+// @Summary Merge duplicate songs
+// @Description Keep one song, fill its empty fields from the duplicate and delete the duplicate
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param keepId query string true "ID of the song to keep"
+// @Param mergeId query string true "ID of the duplicate to merge"
+// @Success 200 {object} gin.H "OK: Songs merged"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song doesn't exist"
+// @Router /merge-songs [post]
+func (h *SongHandler) MergeSongs(c *gin.Context) {
+	const op = "handlers.MergeSongs"
+
+	if err := h.repo.MergeSongs(c.Request.Context(), c.Query("keepId"), c.Query("mergeId")); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Songs merged"})
+}
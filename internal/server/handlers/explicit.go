@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"test-case/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type explicitOverride struct {
+	Explicit *bool `json:"explicit"`
+}
+
+// SetExplicit godoc
+//
+// @Summary Override the explicit flag
+// @Description Force the explicit content flag of a song, or pass null to use the analyzer result again
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param override body explicitOverride true "true, false or null"
+// @Success 200 {object} gin.H "OK: Explicit flag updated"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song doesn't exist"
+// @Router /songs/{id}/explicit [post]
+func (h *SongHandler) SetExplicit(c *gin.Context) {
+	const op = "handlers.SetExplicit"
+
+	var override explicitOverride
+	if err := c.BindJSON(&override); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	if err := h.repo.SetExplicitOverride(c.Request.Context(), c.Param("id"), override.Explicit); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Explicit flag updated"})
+}
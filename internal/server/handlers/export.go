@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/songexport"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportSongs godoc
+//
+// @Summary Export songs
+// @Description Stream all songs matching the get-songs filters as a CSV, JSON, NDJSON or XLSX file, optionally gzipped
+// @Tags songs
+// @Produce text/csv,application/json,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/gzip
+// @Param format query string false "csv (default), json, ndjson or xlsx"
+// @Param gzip query bool false "Compress the file with gzip"
+// @Param band query string false "Filter by band name or alias"
+// @Param bandSearch query string false "Search bands by part of a name or alias"
+// @Param song query string false "Filter by song name"
+// @Param language query string false "Filter by detected lyrics language"
+// @Param explicit query bool false "Filter by explicit content flag"
+// @Success 200 {file} file
+// @Failure 400 {object} gin.H
+// @Router /export [get]
+func (h *SongHandler) ExportSongs(c *gin.Context) {
+	const op = "handlers.ExportSongs"
+
+	format := c.DefaultQuery("format", songexport.FormatCSV)
+
+	compress := false
+	if value := c.Query("gzip"); value != "" {
+		var err error
+		if compress, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	filterParams := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 && key != "format" && key != "gzip" {
+			filterParams[key] = values[0]
+		}
+	}
+
+	// Nothing reaches the client before the buffer fills up, so errors in
+	// the filters can still be answered with a JSON error.
+	buffer := bufio.NewWriterSize(c.Writer, 32*1024)
+
+	var dst io.Writer = buffer
+	var compressor *gzip.Writer
+	if compress {
+		compressor = gzip.NewWriter(buffer)
+		dst = compressor
+	}
+
+	writer, err := songexport.NewWriter(dst, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	filename := "songs." + format
+	c.Header("Content-Type", songexport.ContentType(format))
+	if compress {
+		filename += ".gz"
+		c.Header("Content-Type", "application/gzip")
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	err = h.repo.ExportSongs(c.Request.Context(), filterParams, func(song models.Song) error {
+		return writer.Write(songexport.NewRecord(song))
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil && compressor != nil {
+		err = compressor.Close()
+	}
+	if err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+		// The response is already on its way and stays incomplete.
+		c.Abort()
+		return
+	}
+
+	if err := buffer.Flush(); err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+	}
+}
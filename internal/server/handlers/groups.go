@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/storage/repos"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GroupHandler struct {
+	repo repos.GroupRepository
+}
+
+func NewGroupHandler(repos repos.GroupRepository) GroupHandler {
+	return GroupHandler{repo: repos}
+}
+
+// GetGroupAliases godoc
+//
+// @Summary Get group aliases
+// @Description Retrieve alternative names of a group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param groupId query string true "Group ID"
+// @Success 200 {array} models.GroupAlias
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Group doesn't exist"
+// @Router /get-group-aliases [get]
+func (h *GroupHandler) GetGroupAliases(c *gin.Context) {
+	const op = "handlers.GetGroupAliases"
+
+	result, err := h.repo.GetAliases(c.Request.Context(), c.Query("groupId"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Group doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AddGroupAlias godoc
+//
+// @Summary Add a group alias
+// @Description Add an alternative name, transliteration or abbreviation of a group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param alias body models.GroupAlias true "New alias object"
+// @Success 200 {object} gin.H "OK: Alias created, New alias ID"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Group doesn't exist"
+// @Failure 409 {object} gin.H "Alias already used"
+// @Router /add-group-alias [post]
+func (h *GroupHandler) AddGroupAlias(c *gin.Context) {
+	const op = "handlers.AddGroupAlias"
+
+	var newAlias models.GroupAlias
+	if err := c.BindJSON(&newAlias); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	id, err := h.repo.AddAlias(c.Request.Context(), newAlias)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Group doesnt exist"})
+			return
+		}
+		if err == repos.ErrAliasTaken {
+			c.JSON(http.StatusConflict, gin.H{"Error": "Alias already used"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Alias created", "New alias Id": id})
+}
+
+// DeleteGroupAlias godoc
+//
+// @Summary Delete a group alias
+// @Description Delete an alias by its ID
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param aliasId query string true "Alias ID"
+// @Success 200 {object} gin.H "OK: Alias deleted"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Alias doesn't exist"
+// @Router /delete-group-alias [delete]
+func (h *GroupHandler) DeleteGroupAlias(c *gin.Context) {
+	const op = "handlers.DeleteGroupAlias"
+
+	if err := h.repo.DeleteAlias(c.Request.Context(), c.Query("aliasId")); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Alias doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Alias deleted"})
+}
+
+// GetGroupLyricsStats godoc
+//
+// @Summary Get band lyrics statistics
+// @Description Aggregate lyrics statistics over all songs of a band and rank its songs by repetition
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param top query int false "Number of most frequent words, 10 by default"
+// @Success 200 {object} models.GroupLyricsStats
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Group doesn't exist"
+// @Router /groups/{id}/lyrics/stats [get]
+func (h *GroupHandler) GetGroupLyricsStats(c *gin.Context) {
+	const op = "handlers.GetGroupLyricsStats"
+
+	top, ok := topWords(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.repo.GetLyricsStats(c.Request.Context(), c.Param("id"), top)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Group doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteGroup godoc
+//
+// @Summary Delete a group
+// @Description Move a group and all of its songs to the trash
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param groupId query string true "Group ID"
+// @Success 200 {object} gin.H "OK: Group deleted"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Group doesn't exist"
+// @Router /delete-group [delete]
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	const op = "handlers.DeleteGroup"
+
+	if err := h.repo.DeleteGroup(c.Request.Context(), c.Query("groupId")); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Group doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Group deleted"})
+}
@@ -1,12 +1,10 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
-	"net/url"
-	"os"
 	"strconv"
 	"test-case/internal/models"
+	"test-case/internal/utils/details"
 	"test-case/internal/utils/logger"
 	"test-case/internal/utils/paginates"
 	"test-case/storage/repos"
@@ -16,11 +14,12 @@ import (
 )
 
 type SongHandler struct {
-	repo repos.SongRepository
+	repo    repos.SongRepository
+	details *details.Client
 }
 
-func NewSongHandler(repos repos.SongRepository) SongHandler {
-	return SongHandler{repo: repos}
+func NewSongHandler(repos repos.SongRepository, details *details.Client) SongHandler {
+	return SongHandler{repo: repos, details: details}
 }
 
 // GetSongs godoc
@@ -32,8 +31,11 @@ func NewSongHandler(repos repos.SongRepository) SongHandler {
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Limit of songs per page"
-// @Param group query string false "Filter by group"
+// @Param band query string false "Filter by band name or alias"
+// @Param bandSearch query string false "Search bands by part of a name or alias"
 // @Param song query string false "Filter by song name"
+// @Param language query string false "Filter by detected lyrics language"
+// @Param explicit query bool false "Filter by explicit content flag"
 // @Success 200 {array} models.Song
 // @Failure 400 {object} gin.H
 // @Failure 404 {object} gin.H "Song doesn't exist"
@@ -50,15 +52,15 @@ func (h *SongHandler) GetSongs(c *gin.Context) {
 		}
 	}
 
-	logger.Logger.Debug().Interface("Recieved params: ", filterParams).Msg(op)
+	logger.Ctx(c.Request.Context()).Debug().Interface("Recieved params: ", filterParams).Msg(op)
 
-	result, err := h.repo.GetSongs(filterParams, c.Query("page"), c.Query("limit"))
+	result, err := h.repo.GetSongs(c.Request.Context(), filterParams, c.Query("page"), c.Query("limit"))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
 			return
 		}
-		logger.Logger.Info().Interface("Error occured: ", err.Error()).Msg(op)
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
 		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
 		return
 	}
@@ -69,66 +71,129 @@ func (h *SongHandler) GetSongs(c *gin.Context) {
 // GetSongText godoc
 //
 // @Summary Get song text
-// @Description Retrieve the text of a song by its ID with pagination
+// @Description Retrieve the text of a song by its ID, paged by couplets, lines or a character budget, or a single couplet
 // @Tags songs
 // @Accept json
 // @Produce json
 // @Param songId query string true "Song ID"
-// @Param page query int false "Page number"
-// @Param limit query int false "Limit of couplets per page"
-// @Success 200 {object} map[string]string "Paginated song couplets"
+// @Param mode query string false "block (default), line or chars"
+// @Param page query int false "Page number, 1 by default"
+// @Param limit query int false "Couplets or lines per page, or characters per page in chars mode"
+// @Param couplet query int false "Return only the couplet with this number"
+// @Success 200 {object} gin.H "Paginated song text with total_couplets and total_pages"
 // @Failure 400 {object} gin.H
-// @Failure 404 {object} gin.H "Song doesn't exist"
+// @Failure 404 {object} gin.H "Song or couplet doesn't exist"
 // @Router /get-song-text [get]
 func (h *SongHandler) GetSongText(c *gin.Context) {
 	const op = "handlers.GetSongText"
 
-	result, err := h.repo.GetSongText(c.Query("songId"))
+	result, err := h.repo.GetSongText(c.Request.Context(), c.Query("songId"))
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
 			return
 		}
-		logger.Logger.Info().Interface("Error occured: ", err.Error()).Msg(op)
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
 		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
 		return
 	}
 
-	page, err := strconv.Atoi(c.Query("page"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+	if value := c.Query("couplet"); value != "" {
+		couplet, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+
+		blocks := paginates.SongTextBlocks(result)
+		if couplet < 1 || couplet > len(blocks) {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Couplet doesnt exist", "total_couplets": len(blocks)})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"song_text":      []map[string]string{{"couplet": blocks[couplet-1]}},
+			"couplet":        couplet,
+			"total_couplets": len(blocks),
+		})
 		return
 	}
 
-	limit, err := strconv.Atoi(c.Query("limit"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+	mode := c.DefaultQuery("mode", paginates.TextModeBlock)
+	defaultLimit, maxLimit, ok := textLimits(mode)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "mode must be block, line or chars"})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "page must be a positive number"})
 		return
 	}
 
-	logger.Logger.Debug().Str("String from DB: ", result).Msg(op)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "limit must be a positive number"})
+		return
+	}
+	limit = min(limit, maxLimit)
 
-	paginatedParts := paginates.SongTextPaginate(result, page, limit)
+	paginated := paginates.SongTextPaginate(result, mode, page, limit)
+
+	key := "couplet"
+	switch mode {
+	case paginates.TextModeLine:
+		key = "line"
+	case paginates.TextModeChars:
+		key = "part"
+	}
 
 	type Response struct {
-		Text []map[string]string `json:"song_text"`
+		Text          []map[string]string `json:"song_text"`
+		Mode          string              `json:"mode"`
+		Page          int                 `json:"page"`
+		Limit         int                 `json:"limit"`
+		TotalCouplets int                 `json:"total_couplets"`
+		TotalPages    int                 `json:"total_pages"`
 	}
 
-	var response Response
+	response := Response{
+		Text:          make([]map[string]string, 0, len(paginated.Parts)),
+		Mode:          mode,
+		Page:          page,
+		Limit:         limit,
+		TotalCouplets: paginated.TotalCouplets,
+		TotalPages:    paginated.TotalPages,
+	}
 
-	for _, part := range paginatedParts {
+	for _, part := range paginated.Parts {
 		response.Text = append(response.Text, map[string]string{
-			"couplet": part,
+			key: part,
 		})
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// textLimits returns the default and maximal limit of a text mode.
+func textLimits(mode string) (int, int, bool) {
+	switch mode {
+	case paginates.TextModeBlock:
+		return 5, 100, true
+	case paginates.TextModeLine:
+		return 20, 500, true
+	case paginates.TextModeChars:
+		return 500, 10000, true
+	}
+
+	return 0, 0, false
+}
+
 // DeleteSong godoc
 //
 // @Summary Delete a song
-// @Description Delete a song by its ID
+// @Description Move a song to the trash by its ID
 // @Tags songs
 // @Accept json
 // @Produce json
@@ -140,12 +205,12 @@ func (h *SongHandler) GetSongText(c *gin.Context) {
 func (h *SongHandler) DeleteSong(c *gin.Context) {
 	const op = "handlers.DeleteSong"
 
-	if err := h.repo.DeleteSong(c.Query("songId")); err != nil {
+	if err := h.repo.DeleteSong(c.Request.Context(), c.Query("songId")); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
 			return
 		}
-		logger.Logger.Info().Interface("Error occured: ", err.Error()).Msg(op)
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
 		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
 		return
 	}
@@ -161,6 +226,8 @@ func (h *SongHandler) DeleteSong(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param song body models.Song true "Updated song object"
+// @Param X-Author header string false "Author of the change"
+// @Param X-Change-Reason header string false "Reason of the change"
 // @Success 200 {object} gin.H "OK: Song updated"
 // @Failure 400 {object} gin.H
 // @Failure 404 {object} gin.H "Song doesn't exist"
@@ -174,14 +241,14 @@ func (h *SongHandler) UpdateSong(c *gin.Context) {
 		return
 	}
 
-	logger.Logger.Debug().Interface("Recieved updated song: ", updatedSong).Msg(op)
+	logger.Ctx(c.Request.Context()).Debug().Interface("Recieved updated song: ", updatedSong).Msg(op)
 
-	if err := h.repo.UpdateSong(updatedSong); err != nil {
+	if err := h.repo.UpdateSong(c.Request.Context(), updatedSong); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
 			return
 		}
-		logger.Logger.Info().Interface("Error occured: ", err.Error()).Msg(op)
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
 		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
 		return
 	}
@@ -199,6 +266,8 @@ func (h *SongHandler) UpdateSong(c *gin.Context) {
 // @Param song body models.Song true "New song object"
 // @Success 200 {object} gin.H "OK: Song created, New song ID"
 // @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H "Song already exists"
+// @Failure 502 {object} gin.H "Details provider failed"
 // @Router /add-song [post]
 func (h *SongHandler) AddSong(c *gin.Context) {
 	const op = "handlers.AddSong"
@@ -209,50 +278,26 @@ func (h *SongHandler) AddSong(c *gin.Context) {
 		return
 	}
 
-	baseURL := os.Getenv("BASE_URL")
-	baseURL, _ = url.JoinPath(baseURL, "/info")
-
-	params := url.Values{}
-	params.Add("group", newSong.Band)
-	params.Add("song", newSong.Song)
-
-	apiURL, err := url.Parse(baseURL)
-	if err != nil {
-		logger.Logger.Info().Interface("Error occured: ", err.Error()).Msg(op)
-		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
-		return
-	}
-	apiURL.RawQuery = params.Encode()
-
-	response, err := http.Get(apiURL.String())
+	songDetail, err := h.details.Fetch(c.Request.Context(), newSong.Band, newSong.Song)
 	if err != nil {
-		logger.Logger.Info().Interface("Error occured: ", err.Error()).Msg(op)
-		c.JSON(response.StatusCode, gin.H{"Error": err.Error()})
-		return
-	}
-
-	type SongDetail struct {
-		ReleaseDate string `json:"releaseDate"`
-		Text        string `json:"text"`
-		Link        string `json:"link"`
-	}
-
-	var songDetail SongDetail
-	if err := json.NewDecoder(response.Body).Decode(&songDetail); err != nil {
-		logger.Logger.Info().Interface("Error occured: ", err.Error()).Msg(op)
-		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadGateway, gin.H{"Error": err.Error()})
 		return
 	}
 
-	logger.Logger.Debug().Interface("Song details from API", songDetail).Msg(op)
+	logger.Ctx(c.Request.Context()).Debug().Interface("Song details from API", songDetail).Msg(op)
 
 	newSong.ReleaseDate = songDetail.ReleaseDate
 	newSong.Text = songDetail.Text
 	newSong.Link = songDetail.Link
 
-	id, err := h.repo.AddSong(newSong)
+	id, err := h.repo.AddSong(c.Request.Context(), newSong)
 	if err != nil {
-		logger.Logger.Info().Interface("Error occured: ", err.Error()).Msg(op)
+		if err == repos.ErrDuplicateSong {
+			c.JSON(http.StatusConflict, gin.H{"Error": "Song already exists"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
 		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
 		return
 	}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"test-case/internal/utils/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) HealthHandler {
+	return HealthHandler{checker: checker}
+}
+
+// Liveness godoc
+//
+// @Summary Liveness probe
+// @Description Answer as long as the process is alive, even while migrations run
+// @Tags health
+// @Produce json
+// @Success 200 {object} gin.H "status: ok"
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readiness godoc
+//
+// @Summary Readiness probe
+// @Description Run the configured checks: database ping, applied migrations and a reachable details provider. Fails as soon as shutdown begins
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report := h.checker.Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
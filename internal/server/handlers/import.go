@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/songimport"
+	"test-case/storage/repos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportSongs godoc
+//
+// @Summary Import songs in bulk
+// @Description Stream songs from a CSV file (header with band, song, releaseDate, text, link), a JSON array or NDJSON. Invalid and duplicate rows are listed in the report; a failed import can be continued with resume set to the reported lastRow
+// @Tags songs
+// @Accept text/csv,application/json,application/x-ndjson
+// @Produce json
+// @Param format query string false "csv, json or ndjson, taken from Content-Type when omitted"
+// @Param dryRun query bool false "Validate without storing anything"
+// @Param resume query int false "Skip rows up to and including this row"
+// @Success 200 {object} models.ImportReport
+// @Failure 400 {object} gin.H
+// @Router /import-songs [post]
+func (h *SongHandler) ImportSongs(c *gin.Context) {
+	const op = "handlers.ImportSongs"
+
+	format := c.Query("format")
+	if format == "" {
+		format = songimport.DetectFormat(c.ContentType())
+	}
+
+	var options repos.ImportOptions
+	var err error
+
+	if value := c.Query("dryRun"); value != "" {
+		if options.DryRun, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	if value := c.Query("resume"); value != "" {
+		if options.Resume, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	reader, err := songimport.NewReader(c.Request.Body, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	report, err := h.repo.ImportSongs(c.Request.Context(), reader, options)
+	if err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error(), "Report": report})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/requestinfo"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoggingHandler struct{}
+
+func NewLoggingHandler() LoggingHandler {
+	return LoggingHandler{}
+}
+
+// GetLogLevels godoc
+//
+// @Summary Get the log levels
+// @Description Show the global log level and the levels of single packages
+// @Tags logging
+// @Produce json
+// @Success 200 {object} logger.Levels
+// @Router /log-levels [get]
+func (h *LoggingHandler) GetLogLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, logger.GetLevels())
+}
+
+// SetLogLevels godoc
+//
+// @Summary Change the log levels
+// @Description Change the global level and the package levels without a restart. An empty global level keeps the current one, given packages replace all package levels, an empty object clears them
+// @Tags logging
+// @Accept json
+// @Produce json
+// @Param levels body logger.Levels true "New levels: trace, debug, info, warn, error, fatal, panic or disabled"
+// @Success 200 {object} logger.Levels
+// @Failure 400 {object} gin.H
+// @Router /log-levels [post]
+func (h *LoggingHandler) SetLogLevels(c *gin.Context) {
+	const op = "handlers.SetLogLevels"
+
+	var request logger.Levels
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	levels := logger.GetLevels()
+	if request.Global != "" {
+		levels.Global = request.Global
+	}
+	if request.Packages != nil {
+		levels.Packages = request.Packages
+	}
+
+	if err := logger.SetLevels(levels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	// A warning, so the change shows up under all but the quietest levels.
+	logger.Ctx(c.Request.Context()).Warn().
+		Str("author", requestinfo.FromContext(c.Request.Context()).Author).
+		Interface("levels", levels).
+		Msg(op)
+
+	c.JSON(http.StatusOK, levels)
+}
@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/lrc"
+	"test-case/internal/utils/lyrics"
+	"test-case/storage/repos"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+)
+
+type LyricsHandler struct {
+	repo repos.LyricsRepository
+}
+
+func NewLyricsHandler(repos repos.LyricsRepository) LyricsHandler {
+	return LyricsHandler{repo: repos}
+}
+
+// GetLyrics godoc
+//
+// @Summary Get song lyrics
+// @Description Retrieve the lyrics of a song either as typed sections or as flattened text. The language is taken from ?lang=, then from Accept-Language, and defaults to the original
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param format query string false "structured or text (default)"
+// @Param lang query string false "BCP 47 language tag"
+// @Param align query string false "BCP 47 tag of a second language to return aligned line by line"
+// @Param Accept-Language header string false "Preferred languages"
+// @Success 200 {object} gin.H "Lyrics sections, text or aligned lines"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song or lyrics in this language don't exist"
+// @Router /songs/{id}/lyrics [get]
+func (h *LyricsHandler) GetLyrics(c *gin.Context) {
+	const op = "handlers.GetLyrics"
+
+	format := c.DefaultQuery("format", "text")
+	if format != "text" && format != "structured" {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "format must be structured or text"})
+		return
+	}
+
+	entries, err := h.repo.GetTranslations(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	languages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		languages = append(languages, entry.Language)
+	}
+
+	entry, ok := selectLyrics(entries, c.Query("lang"), c.GetHeader("Accept-Language"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"Error": "No lyrics in this language", "languages": languages})
+		return
+	}
+
+	if align := c.Query("align"); align != "" {
+		other, ok := selectLyrics(entries, align, "")
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "No lyrics in this language", "languages": languages})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"languages": []string{entry.Language, other.Language},
+			"lines":     lyrics.Align(entry.Text, other.Text),
+		})
+		return
+	}
+
+	// Stored sections belong to the original; other languages are parsed.
+	sections := lyrics.Parse(entry.Text)
+	if entry.Kind == models.LyricsOriginal {
+		sections, err = h.repo.GetSections(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+	}
+
+	response := gin.H{"language": entry.Language, "kind": entry.Kind, "languages": languages}
+	if format == "text" {
+		response["text"] = lyrics.Flatten(sections)
+	} else {
+		response["sections"] = sections
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetLyricsTranslation godoc
+//
+// @Summary Set a lyrics translation
+// @Description Create or replace the translation or transliteration of a song in one language
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param lyrics body models.Lyrics true "Language, kind (translation or transliteration) and text"
+// @Success 200 {object} gin.H "OK: Lyrics saved"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song doesn't exist"
+// @Failure 409 {object} gin.H "Language is used by the original"
+// @Router /songs/{id}/lyrics [post]
+func (h *LyricsHandler) SetLyricsTranslation(c *gin.Context) {
+	const op = "handlers.SetLyricsTranslation"
+
+	songId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	var translation models.Lyrics
+	if err := c.BindJSON(&translation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	if translation.Kind != models.LyricsTranslation && translation.Kind != models.LyricsTransliteration {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "kind must be translation or transliteration"})
+		return
+	}
+
+	tag, err := language.Parse(translation.Language)
+	if err != nil || tag == language.Und {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "language must be a BCP 47 tag"})
+		return
+	}
+
+	translation.SongId = uint(songId)
+	translation.Language = tag.String()
+
+	if err := h.repo.SetTranslation(c.Request.Context(), translation); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
+			return
+		}
+		if err == repos.ErrLanguageTaken {
+			c.JSON(http.StatusConflict, gin.H{"Error": "Language is used by the original lyrics"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Lyrics saved"})
+}
+
+// DeleteLyricsTranslation godoc
+//
+// @Summary Delete a lyrics translation
+// @Description Delete the translation or transliteration of a song in one language. The original cannot be deleted
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param lang path string true "BCP 47 language tag"
+// @Success 200 {object} gin.H "OK: Lyrics deleted"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Translation doesn't exist"
+// @Router /songs/{id}/lyrics/{lang} [delete]
+func (h *LyricsHandler) DeleteLyricsTranslation(c *gin.Context) {
+	const op = "handlers.DeleteLyricsTranslation"
+
+	tag, err := language.Parse(c.Param("lang"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "language must be a BCP 47 tag"})
+		return
+	}
+
+	if err := h.repo.DeleteTranslation(c.Request.Context(), c.Param("id"), tag.String()); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Translation doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Lyrics deleted"})
+}
+
+// selectLyrics picks the entry for an explicitly requested language, or
+// the best match for Accept-Language, falling back to the original.
+// Entries are expected to start with the original.
+func selectLyrics(entries []models.Lyrics, lang string, acceptLanguage string) (models.Lyrics, bool) {
+	tags := make([]language.Tag, 0, len(entries))
+	for _, entry := range entries {
+		tags = append(tags, language.Make(entry.Language))
+	}
+	matcher := language.NewMatcher(tags)
+
+	if lang != "" {
+		requested, err := language.Parse(lang)
+		if err != nil {
+			return models.Lyrics{}, false
+		}
+
+		_, index, confidence := matcher.Match(requested)
+		if confidence < language.High {
+			return models.Lyrics{}, false
+		}
+		return entries[index], true
+	}
+
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return entries[0], true
+	}
+
+	_, index, confidence := matcher.Match(preferred...)
+	if confidence == language.No {
+		return entries[0], true
+	}
+
+	return entries[index], true
+}
+
+// maxLrcSize caps an uploaded .lrc file, which is read into memory
+// whole. Timed lyrics of even a long song take a few dozen kilobytes.
+const maxLrcSize = 1 << 20
+
+// ImportLrc godoc
+//
+// @Summary Import synced lyrics
+// @Description Replace the timed lyrics of a song with an .lrc file, including enhanced word timing. The plain text is derived from it
+// @Tags lyrics
+// @Accept plain
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param lrc body string true "Contents of the .lrc file"
+// @Success 200 {object} gin.H "OK: Synced lyrics imported, Lines count"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song doesn't exist"
+// @Failure 413 {object} gin.H "The file is larger than 1 MiB"
+// @Router /songs/{id}/lyrics/lrc [post]
+func (h *LyricsHandler) ImportLrc(c *gin.Context) {
+	const op = "handlers.ImportLrc"
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxLrcSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"Error": fmt.Sprintf("lrc file is larger than %d bytes", maxLrcSize)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	synced, err := lrc.Parse(string(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	if err := h.repo.SetSynced(c.Request.Context(), c.Param("id"), synced); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Synced lyrics imported", "Lines": len(synced.Lines)})
+}
+
+// ExportLrc godoc
+//
+// @Summary Export synced lyrics
+// @Description Download the timed lyrics of a song as an .lrc file
+// @Tags lyrics
+// @Produce plain
+// @Param id path string true "Song ID"
+// @Success 200 {string} string "Contents of the .lrc file"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song or synced lyrics don't exist"
+// @Router /songs/{id}/lyrics/lrc [get]
+func (h *LyricsHandler) ExportLrc(c *gin.Context) {
+	synced, ok := h.getSynced(c, "handlers.ExportLrc")
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.lrc"`, c.Param("id")))
+	c.String(http.StatusOK, lrc.Format(synced))
+}
+
+// GetSyncedLyrics godoc
+//
+// @Summary Get synced lyrics
+// @Description Retrieve the line active at a playback offset, the lines within a time window, or all timed lines
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param at query int false "Playback offset in milliseconds"
+// @Param from query int false "Window start in milliseconds"
+// @Param to query int false "Window end in milliseconds"
+// @Success 200 {object} gin.H "Active line or list of lines"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song or synced lyrics don't exist"
+// @Router /songs/{id}/lyrics/synced [get]
+func (h *LyricsHandler) GetSyncedLyrics(c *gin.Context) {
+	synced, ok := h.getSynced(c, "handlers.GetSyncedLyrics")
+	if !ok {
+		return
+	}
+
+	if value := c.Query("at"); value != "" {
+		at, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+
+		index := synced.At(at)
+		if index < 0 {
+			c.JSON(http.StatusOK, gin.H{"index": index, "line": nil})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"index": index, "line": synced.Lines[index]})
+		return
+	}
+
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, err := strconv.ParseInt(c.DefaultQuery("from", "0"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+
+		to, err := strconv.ParseInt(c.DefaultQuery("to", strconv.FormatInt(math.MaxInt64, 10)), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"lines": synced.Window(from, to)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lines": synced.Lines})
+}
+
+func (h *LyricsHandler) getSynced(c *gin.Context, op string) (lrc.Lyrics, bool) {
+	synced, err := h.repo.GetSynced(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
+			return lrc.Lyrics{}, false
+		}
+		if err == repos.ErrNoSyncedLyrics {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song has no synced lyrics"})
+			return lrc.Lyrics{}, false
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return lrc.Lyrics{}, false
+	}
+
+	return synced, true
+}
+
+// GetLyricsStats godoc
+//
+// @Summary Get lyrics statistics
+// @Description Count lines, words and unique words of a song's lyrics, its repetition ratio, most frequent words without stop words and estimated reading time
+// @Tags lyrics
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param top query int false "Number of most frequent words, 10 by default"
+// @Success 200 {object} lyricstats.Stats
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song doesn't exist"
+// @Router /songs/{id}/lyrics/stats [get]
+func (h *LyricsHandler) GetLyricsStats(c *gin.Context) {
+	const op = "handlers.GetLyricsStats"
+
+	top, ok := topWords(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.repo.GetStats(c.Request.Context(), c.Param("id"), top)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// topWords reads the number of most frequent words to return.
+func topWords(c *gin.Context) (int, bool) {
+	top, err := strconv.Atoi(c.DefaultQuery("top", "10"))
+	if err != nil || top < 0 || top > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": "top must be a number from 0 to 100"})
+		return 0, false
+	}
+
+	return top, true
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"test-case/internal/utils/diff"
+	"test-case/internal/utils/textnorm"
+
+	"github.com/gin-gonic/gin"
+)
+
+type normalizeRequest struct {
+	Text string `json:"text" binding:"required"`
+	// Steps overrides the configured steps, e.g. "newlines,whitespace".
+	Steps string `json:"steps"`
+}
+
+type normalizeResponse struct {
+	textnorm.Result
+	Diff []diff.Line `json:"diff"`
+}
+
+// NormalizeText godoc
+//
+// @Summary Preview lyrics normalization
+// @Description Show what the ingest normalization would change in a text without storing anything
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param request body normalizeRequest true "Text and optional comma separated steps"
+// @Success 200 {object} normalizeResponse
+// @Failure 400 {object} gin.H
+// @Router /normalize-text [post]
+func (h *SongHandler) NormalizeText(c *gin.Context) {
+	var request normalizeRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	pipeline := textnorm.Default
+	if request.Steps != "" {
+		steps, err := textnorm.Parse(request.Steps)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+		pipeline = textnorm.New(steps)
+	}
+
+	result := pipeline.Run(request.Text)
+
+	c.JSON(http.StatusOK, normalizeResponse{
+		Result: result,
+		Diff:   diff.Lines(request.Text, result.Text),
+	})
+}
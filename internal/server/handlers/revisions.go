@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"test-case/internal/utils/logger"
+	"test-case/storage/repos"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RevisionHandler struct {
+	repo repos.RevisionRepository
+}
+
+func NewRevisionHandler(repos repos.RevisionRepository) RevisionHandler {
+	return RevisionHandler{repo: repos}
+}
+
+// GetRevisions godoc
+//
+// @Summary Get song revisions
+// @Description Retrieve the revision history of a song, newest first
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Success 200 {array} models.SongRevision
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song doesn't exist"
+// @Router /songs/{id}/revisions [get]
+func (h *RevisionHandler) GetRevisions(c *gin.Context) {
+	const op = "handlers.GetRevisions"
+
+	result, err := h.repo.GetRevisions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DiffRevisions godoc
+//
+// @Summary Diff two revisions
+// @Description Show changed metadata and a line-level diff of the lyrics between two revisions of a song
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param from query int true "Older revision number"
+// @Param to query int true "Newer revision number"
+// @Success 200 {object} models.RevisionDiff
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Revision doesn't exist"
+// @Router /songs/{id}/revisions/diff [get]
+func (h *RevisionHandler) DiffRevisions(c *gin.Context) {
+	const op = "handlers.DiffRevisions"
+
+	result, err := h.repo.DiffRevisions(c.Request.Context(), c.Param("id"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		if err == repos.ErrRevisionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Revision doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RollbackSong godoc
+//
+// @Summary Roll back a song
+// @Description Restore the lyrics and metadata of a song from an older revision. The rollback is recorded as a new revision
+// @Tags revisions
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Param number path int true "Revision number to restore"
+// @Param X-Author header string false "Author of the change"
+// @Param X-Change-Reason header string false "Reason of the change"
+// @Success 200 {object} gin.H "OK: Song rolled back, New revision number"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Revision doesn't exist"
+// @Router /songs/{id}/revisions/{number}/rollback [post]
+func (h *RevisionHandler) RollbackSong(c *gin.Context) {
+	const op = "handlers.RollbackSong"
+
+	number, err := h.repo.RollbackSong(c.Request.Context(), c.Param("id"), c.Param("number"))
+	if err != nil {
+		if err == repos.ErrRevisionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Revision doesnt exist"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Song rolled back", "New revision": number})
+}
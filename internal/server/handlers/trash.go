@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"net/http"
+	"test-case/internal/utils/logger"
+	"test-case/storage/repos"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TrashHandler struct {
+	repo repos.TrashRepository
+}
+
+func NewTrashHandler(repos repos.TrashRepository) TrashHandler {
+	return TrashHandler{repo: repos}
+}
+
+// GetTrash godoc
+//
+// @Summary List the trash
+// @Description Retrieve a page of deleted songs and groups that can still be restored, most recently deleted first. Songs are listed without their text
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit of songs and groups per page"
+// @Success 200 {object} models.Trash
+// @Failure 400 {object} gin.H
+// @Router /trash [get]
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	const op = "handlers.GetTrash"
+
+	result, err := h.repo.GetTrash(c.Request.Context(), c.Query("page"), c.Query("limit"))
+	if err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RestoreSong godoc
+//
+// @Summary Restore a song
+// @Description Take a song out of the trash, restoring its group too if it was deleted
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Success 200 {object} gin.H "OK: Song restored"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song is not in the trash"
+// @Failure 409 {object} gin.H "A song or group with the same name exists"
+// @Router /trash/songs/{id}/restore [post]
+func (h *TrashHandler) RestoreSong(c *gin.Context) {
+	const op = "handlers.RestoreSong"
+
+	if err := h.repo.RestoreSong(c.Request.Context(), c.Param("id")); err != nil {
+		h.restoreError(c, op, err, "Song is not in the trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Song restored"})
+}
+
+// RestoreGroup godoc
+//
+// @Summary Restore a group
+// @Description Take a group out of the trash together with the songs deleted with it
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} gin.H "OK: Group restored"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Group is not in the trash"
+// @Failure 409 {object} gin.H "A group with the same name exists"
+// @Router /trash/groups/{id}/restore [post]
+func (h *TrashHandler) RestoreGroup(c *gin.Context) {
+	const op = "handlers.RestoreGroup"
+
+	if err := h.repo.RestoreGroup(c.Request.Context(), c.Param("id")); err != nil {
+		h.restoreError(c, op, err, "Group is not in the trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Group restored"})
+}
+
+func (h *TrashHandler) restoreError(c *gin.Context, op string, err error, notFound string) {
+	switch err {
+	case gorm.ErrRecordNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"Error": notFound})
+	case repos.ErrDuplicateSong, repos.ErrGroupNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"Error": err.Error()})
+	default:
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+	}
+}
+
+// PurgeSong godoc
+//
+// @Summary Purge a song
+// @Description Delete a song in the trash for good, with its lyrics and history
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID"
+// @Success 200 {object} gin.H "OK: Song purged"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Song is not in the trash"
+// @Router /trash/songs/{id} [delete]
+func (h *TrashHandler) PurgeSong(c *gin.Context) {
+	const op = "handlers.PurgeSong"
+
+	if err := h.repo.PurgeSong(c.Request.Context(), c.Param("id")); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Song is not in the trash"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Song purged"})
+}
+
+// PurgeGroup godoc
+//
+// @Summary Purge a group
+// @Description Delete a group in the trash for good, with its aliases and songs
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} gin.H "OK: Group purged"
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H "Group is not in the trash"
+// @Router /trash/groups/{id} [delete]
+func (h *TrashHandler) PurgeGroup(c *gin.Context) {
+	const op = "handlers.PurgeGroup"
+
+	if err := h.repo.PurgeGroup(c.Request.Context(), c.Param("id")); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Error": "Group is not in the trash"})
+			return
+		}
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"OK": "Group purged"})
+}
+
+// PurgeTrash godoc
+//
+// @Summary Empty the trash
+// @Description Delete everything in the trash for good, or only what was deleted longer ago than olderThan
+// @Tags trash
+// @Accept json
+// @Produce json
+// @Param olderThan query string false "Age such as 720h, everything when omitted"
+// @Success 200 {object} models.PurgeResult
+// @Failure 400 {object} gin.H
+// @Router /trash [delete]
+func (h *TrashHandler) PurgeTrash(c *gin.Context) {
+	const op = "handlers.PurgeTrash"
+
+	before := time.Now()
+	if value := c.Query("olderThan"); value != "" {
+		age, err := time.ParseDuration(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+			return
+		}
+		before = before.Add(-age)
+	}
+
+	result, err := h.repo.Purge(c.Request.Context(), before)
+	if err != nil {
+		logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+		c.JSON(http.StatusBadRequest, gin.H{"Error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
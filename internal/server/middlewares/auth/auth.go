@@ -0,0 +1,71 @@
+package middleware_auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/requestinfo"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+// Authenticate requires an X-API-Key header or an Authorization: Bearer
+// JWT. The principal is kept in the gin context and becomes the author of
+// the request's changes. A nil Authenticator lets every request through.
+func Authenticate(a *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "middlewares.auth.Authenticate"
+
+		if a == nil {
+			c.Next()
+			return
+		}
+
+		var principal Principal
+		var err error
+
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			principal, err = a.APIKey(key)
+		} else {
+			scheme, token, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+			if !strings.EqualFold(scheme, "Bearer") {
+				token = ""
+			}
+			principal, err = a.JWT(strings.TrimSpace(token))
+		}
+
+		if err != nil {
+			logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Str("client_ip", c.ClientIP()).Msg(op)
+
+			message := "Invalid credentials"
+			if errors.Is(err, ErrNoCredentials) {
+				message = "Authentication required"
+			}
+			c.Header("WWW-Authenticate", `Bearer realm="songs"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"Error": message})
+			return
+		}
+
+		c.Set(principalKey, principal)
+
+		info := requestinfo.FromContext(c.Request.Context())
+		info.Author = principal.Subject
+		c.Request = c.Request.WithContext(requestinfo.WithInfo(c.Request.Context(), info))
+
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the caller authenticated for the request.
+func PrincipalFrom(c *gin.Context) (Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+
+	principal, ok := value.(Principal)
+	return principal, ok
+}
@@ -0,0 +1,205 @@
+package middleware_auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"test-case/internal/config"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Method  string
+	Roles   []string
+}
+
+var (
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator checks API keys against their hashes from the config and
+// verifies HS256 and RS256 JWTs.
+type Authenticator struct {
+	apiKeys  []apiKey
+	secret   []byte
+	rsaKeys  map[string]*rsa.PublicKey
+	issuer   string
+	audience string
+}
+
+type apiKey struct {
+	name string
+	role string
+	hash []byte
+}
+
+// claims are the registered JWT claims plus the caller's roles.
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// New builds an Authenticator from the config. It returns nil when
+// authentication is disabled.
+func New(cfg config.Auth) (*Authenticator, error) {
+	const op = "middlewares.auth.New"
+
+	if cfg.Disabled {
+		return nil, nil
+	}
+
+	a := &Authenticator{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+	}
+
+	for _, entry := range strings.Split(cfg.APIKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("%s: API key %q is not in the name:role:sha256 form", op, entry)
+		}
+		name, role, digest := parts[0], parts[1], parts[2]
+
+		hash, err := hex.DecodeString(digest)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("%s: API key %q has no valid sha256 hash", op, name)
+		}
+
+		a.apiKeys = append(a.apiKeys, apiKey{name: name, role: role, hash: hash})
+	}
+
+	if cfg.JWTSecret != "" {
+		a.secret = []byte(cfg.JWTSecret)
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		a.rsaKeys = keys
+	}
+
+	if len(a.apiKeys) == 0 && a.secret == nil && len(a.rsaKeys) == 0 {
+		return nil, fmt.Errorf("%s: no API keys, JWT secret or JWKS file configured, set AUTH_DISABLED=true to run without authentication", op)
+	}
+
+	return a, nil
+}
+
+// APIKey finds the configured key whose hash matches the presented one.
+func (a *Authenticator) APIKey(key string) (Principal, error) {
+	if key == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	// Every configured key is compared so the time taken does not tell
+	// which one came close.
+	var match *apiKey
+	for i, candidate := range a.apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], candidate.hash) == 1 {
+			match = &a.apiKeys[i]
+		}
+	}
+
+	if match == nil {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	return Principal{Subject: match.name, Method: MethodAPIKey, Roles: []string{match.role}}, nil
+}
+
+// APIKeyRoles lists the roles given to the configured API keys.
+func (a *Authenticator) APIKeyRoles() []string {
+	roles := make([]string, 0, len(a.apiKeys))
+	for _, key := range a.apiKeys {
+		roles = append(roles, key.role)
+	}
+
+	return roles
+}
+
+// JWT verifies the signature, expiry, issuer and audience of a token and
+// returns its subject and the roles from its roles claim.
+func (a *Authenticator) JWT(token string) (Principal, error) {
+	if token == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	var methods []string
+	if a.secret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(a.rsaKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if a.issuer != "" {
+		options = append(options, jwt.WithIssuer(a.issuer))
+	}
+	if a.audience != "" {
+		options = append(options, jwt.WithAudience(a.audience))
+	}
+
+	parsed := claims{}
+	if _, err := jwt.ParseWithClaims(token, &parsed, a.key, options...); err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	if parsed.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	return Principal{Subject: parsed.Subject, Method: MethodJWT, Roles: parsed.Roles}, nil
+}
+
+func (a *Authenticator) key(token *jwt.Token) (any, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := a.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		// A token without a key id is fine as long as there is only
+		// one key to try.
+		if kid == "" && len(a.rsaKeys) == 1 {
+			for _, key := range a.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
@@ -7,24 +7,27 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// RequestLogger logs every request once it is answered, with the
+// request logger so the entry carries the request and trace ids.
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
-		logger.Logger.Info().
-			Str("method", c.Request.Method).
-			Str("url", c.Request.URL.String()).
-			Str("client_ip", c.ClientIP()).
-			Msg("Incoming request")
-
 		c.Next()
 
-		logger.Logger.Info().
+		event := logger.Ctx(c.Request.Context()).Info()
+		if c.Writer.Status() >= 500 {
+			event = logger.Ctx(c.Request.Context()).Error()
+		}
+
+		event.
 			Str("method", c.Request.Method).
+			Str("route", c.FullPath()).
 			Str("url", c.Request.URL.String()).
 			Str("client_ip", c.ClientIP()).
 			Int("status_code", c.Writer.Status()).
+			Int("bytes", c.Writer.Size()).
 			Dur("duration", time.Since(start)).
-			Msg("Response")
+			Msg("Request")
 	}
 }
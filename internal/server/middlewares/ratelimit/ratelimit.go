@@ -0,0 +1,147 @@
+package middleware_ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	middleware_auth "test-case/internal/server/middlewares/auth"
+	"test-case/internal/utils/logger"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route groups with limits of their own.
+const (
+	GroupRead  = "read"
+	GroupList  = "list"
+	GroupWrite = "write"
+	// GroupIP covers every API request of a client IP. It runs before
+	// authentication, so requests with bad credentials count as well.
+	GroupIP = "ip"
+)
+
+// Limit is a token bucket holding Requests tokens that refills
+// completely over Per. The zero Limit does not limit at all.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// ParseLimit reads limits written as requests/period, like "60/1m". An
+// empty spec or "0" means no limit.
+func ParseLimit(spec string) (Limit, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "0" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q is not in the requests/period form", spec)
+	}
+
+	var limit Limit
+	var err error
+	if limit.Requests, err = strconv.Atoi(strings.TrimSpace(requests)); err != nil || limit.Requests < 0 {
+		return Limit{}, fmt.Errorf("rate limit %q has an invalid number of requests", spec)
+	}
+	if limit.Per, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || limit.Per <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q has an invalid period", spec)
+	}
+
+	return limit, nil
+}
+
+// Result is the state of a bucket after a request took its tokens.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until a denied request would fit.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. The memory store serves a single instance,
+// several instances behind a balancer need a shared implementation.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, cost int) (Result, error)
+}
+
+// Limiter applies the limit of a route group to every caller, who is
+// told apart by the authenticated principal or else the client IP.
+type Limiter struct {
+	store  Store
+	limits map[string]Limit
+}
+
+func New(store Store, limits map[string]Limit) *Limiter {
+	return &Limiter{store: store, limits: limits}
+}
+
+// Handler limits the requests of a route group. cost, if set, tells how
+// many tokens a request takes. A nil Limiter or a group without a limit
+// lets every request through.
+func (l *Limiter) Handler(group string, cost func(c *gin.Context) int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "middlewares.ratelimit.Handler"
+
+		if l == nil {
+			c.Next()
+			return
+		}
+
+		limit := l.limits[group]
+		if limit.Unlimited() {
+			c.Next()
+			return
+		}
+
+		tokens := 1
+		if cost != nil {
+			tokens = cost(c)
+		}
+
+		result, err := l.store.Take(c.Request.Context(), group+"|"+clientKey(c), limit, tokens)
+		if err != nil {
+			// A broken store should not take the API down with it.
+			logger.Ctx(c.Request.Context()).Info().Interface("Error occured: ", err.Error()).Msg(op)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, seconds(limit.Per)))
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"Error": "Too many requests"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	if principal, ok := middleware_auth.PrincipalFrom(c); ok {
+		return principal.Method + ":" + principal.Subject
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// seconds rounds up, so a client waiting that long is never early.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
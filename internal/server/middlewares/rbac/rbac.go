@@ -0,0 +1,156 @@
+package middleware_rbac
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	middleware_auth "test-case/internal/server/middlewares/auth"
+	"test-case/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Role string
+
+const (
+	RoleReader Role = "reader"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+type Permission string
+
+const (
+	// Public routes need no credentials at all.
+	Public Permission = "public"
+
+	Read   Permission = "read"
+	Write  Permission = "write"
+	Delete Permission = "delete"
+	Merge  Permission = "merge"
+	Purge  Permission = "purge"
+	Audit  Permission = "audit"
+	// Logging is changing the log levels at runtime.
+	Logging Permission = "logging"
+)
+
+// rolePermissions lists what each role may do. Every role includes the
+// permissions of the one below it.
+var rolePermissions = map[Role][]Permission{
+	RoleReader: {Read},
+	RoleEditor: {Read, Write},
+	RoleAdmin:  {Read, Write, Delete, Merge, Purge, Audit, Logging},
+}
+
+// Policy maps a route, written as "METHOD /path" with the path as it is
+// registered, to the permission it requires.
+type Policy map[string]Permission
+
+// ParseRole checks that a role name is known.
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", name)
+	}
+
+	return role, nil
+}
+
+// Allows reports whether any of the roles grants the permission.
+func Allows(roles []string, permission Permission) bool {
+	if permission == Public {
+		return true
+	}
+
+	for _, name := range roles {
+		for _, granted := range rolePermissions[Role(strings.ToLower(name))] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Check makes sure the policy has an entry for every registered route and
+// none for routes that do not exist.
+func (p Policy) Check(routes gin.RoutesInfo) error {
+	registered := make(map[string]bool, len(routes))
+	var missing []string
+
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if _, ok := p[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+
+	var stale []string
+	for key := range p {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(stale)
+
+	switch {
+	case len(missing) > 0:
+		return fmt.Errorf("routes without an access policy: %s", strings.Join(missing, ", "))
+	case len(stale) > 0:
+		return fmt.Errorf("access policy for unknown routes: %s", strings.Join(stale, ", "))
+	}
+
+	return nil
+}
+
+// Authorize answers 403 with a problem document when the principal's
+// roles lack the permission the policy sets for the route. With enabled
+// unset, as when authentication is disabled, every request passes.
+func Authorize(policy Policy, enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "middlewares.rbac.Authorize"
+
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		permission, ok := policy[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			// Check keeps this from happening, denying is the safe answer
+			// if it does.
+			forbidden(c, "The route has no access policy")
+			return
+		}
+
+		principal, _ := middleware_auth.PrincipalFrom(c)
+		if !Allows(principal.Roles, permission) {
+			logger.Ctx(c.Request.Context()).Info().
+				Str("subject", principal.Subject).
+				Strs("roles", principal.Roles).
+				Str("permission", string(permission)).
+				Msg(op)
+			forbidden(c, fmt.Sprintf("The %q permission is required", permission))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// forbidden writes an RFC 9457 problem document.
+func forbidden(c *gin.Context, detail string) {
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"type":     "about:blank",
+		"title":    http.StatusText(http.StatusForbidden),
+		"status":   http.StatusForbidden,
+		"detail":   detail,
+		"instance": c.Request.URL.Path,
+	})
+}
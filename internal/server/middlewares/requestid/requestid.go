@@ -0,0 +1,67 @@
+package middleware_requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"test-case/internal/utils/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// RequestID takes the request id from the X-Request-ID header or makes
+// one up, and sends it back. The request context gets the id and a
+// logger that tags every entry with it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !valid(id) {
+			id = newId()
+		}
+		c.Header(Header, id)
+
+		ctx := context.WithValue(c.Request.Context(), contextKey{}, id)
+		requestLogger := logger.Logger.With().Ctx(ctx).Str("request_id", id).Logger()
+		c.Request = c.Request.WithContext(requestLogger.WithContext(ctx))
+
+		c.Next()
+	}
+}
+
+// FromContext returns the id of the request the context belongs to.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// valid accepts ids of up to 128 letters, digits and -_.: so a client
+// cannot smuggle anything odd into the logs.
+func valid(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func newId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,26 @@
+package middleware_requestinfo
+
+import (
+	middleware_requestid "test-case/internal/server/middlewares/requestid"
+	"test-case/internal/utils/requestinfo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestInfo reads the author and the reason of a change from the
+// X-Author and X-Change-Reason headers, next to the request id set by
+// middleware_requestid.
+func RequestInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info := requestinfo.Info{
+			Author:    c.GetHeader("X-Author"),
+			Reason:    c.GetHeader("X-Change-Reason"),
+			RequestId: middleware_requestid.FromContext(c.Request.Context()),
+			ClientIP:  c.ClientIP(),
+		}
+
+		c.Request = c.Request.WithContext(requestinfo.WithInfo(c.Request.Context(), info))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pageCost charges list requests by the rows they ask for: a token per
+// ten rows of the page, and one more per hundred pages of offset.
+func pageCost(c *gin.Context) int {
+	cost := 1
+
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 10 {
+		cost = (min(limit, 100) + 9) / 10
+	}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		cost += page / 100
+	}
+
+	return cost
+}
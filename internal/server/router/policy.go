@@ -0,0 +1,58 @@
+package router
+
+import middleware_rbac "test-case/internal/server/middlewares/rbac"
+
+// policy sets the permission every route requires. Readers may list and
+// read, and editors may also add and update. Admins may also delete,
+// merge and purge, read the audit log and change the log levels.
+// SetupRouter refuses to start while a route is missing here.
+var policy = middleware_rbac.Policy{
+	"GET /swagger/*any": middleware_rbac.Public,
+	"GET /info":         middleware_rbac.Public,
+	"GET /healthz":      middleware_rbac.Public,
+	"GET /readyz":       middleware_rbac.Public,
+
+	"GET /get-songs":       middleware_rbac.Read,
+	"GET /get-song-text":   middleware_rbac.Read,
+	"DELETE /delete-song":  middleware_rbac.Delete,
+	"POST /update-song":    middleware_rbac.Write,
+	"POST /add-song":       middleware_rbac.Write,
+	"POST /import-songs":   middleware_rbac.Write,
+	"GET /export":          middleware_rbac.Read,
+	"GET /get-duplicates":  middleware_rbac.Read,
+	"POST /merge-songs":    middleware_rbac.Merge,
+	"POST /normalize-text": middleware_rbac.Read,
+
+	"POST /songs/:id/explicit":                   middleware_rbac.Write,
+	"GET /songs/:id/lyrics":                      middleware_rbac.Read,
+	"POST /songs/:id/lyrics":                     middleware_rbac.Write,
+	"DELETE /songs/:id/lyrics/:lang":             middleware_rbac.Write,
+	"GET /songs/:id/lyrics/synced":               middleware_rbac.Read,
+	"GET /songs/:id/lyrics/lrc":                  middleware_rbac.Read,
+	"POST /songs/:id/lyrics/lrc":                 middleware_rbac.Write,
+	"GET /songs/:id/lyrics/stats":                middleware_rbac.Read,
+	"GET /songs/:id/revisions":                   middleware_rbac.Read,
+	"GET /songs/:id/revisions/diff":              middleware_rbac.Read,
+	"POST /songs/:id/revisions/:number/rollback": middleware_rbac.Write,
+
+	"GET /get-group-aliases":       middleware_rbac.Read,
+	"POST /add-group-alias":        middleware_rbac.Write,
+	"DELETE /delete-group-alias":   middleware_rbac.Write,
+	"GET /groups/:id/lyrics/stats": middleware_rbac.Read,
+	"DELETE /delete-group":         middleware_rbac.Delete,
+
+	"GET /trash":                     middleware_rbac.Read,
+	"DELETE /trash":                  middleware_rbac.Purge,
+	"POST /trash/songs/:id/restore":  middleware_rbac.Delete,
+	"POST /trash/groups/:id/restore": middleware_rbac.Delete,
+	"DELETE /trash/songs/:id":        middleware_rbac.Purge,
+	"DELETE /trash/groups/:id":       middleware_rbac.Purge,
+
+	"GET /audit":        middleware_rbac.Audit,
+	"GET /audit/export": middleware_rbac.Audit,
+
+	"GET /metrics": middleware_rbac.Read,
+
+	"GET /log-levels":  middleware_rbac.Logging,
+	"POST /log-levels": middleware_rbac.Logging,
+}
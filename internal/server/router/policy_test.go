@@ -0,0 +1,128 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"test-case/internal/config"
+	middleware_auth "test-case/internal/server/middlewares/auth"
+	middleware_rbac "test-case/internal/server/middlewares/rbac"
+	"test-case/internal/utils/details"
+	"test-case/internal/utils/health"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// roleKeys are the API keys of the test callers, from the lowest role to
+// the highest.
+var roleKeys = []struct {
+	role middleware_rbac.Role
+	key  string
+}{
+	{middleware_rbac.RoleReader, "reader-key"},
+	{middleware_rbac.RoleEditor, "editor-key"},
+	{middleware_rbac.RoleAdmin, "admin-key"},
+}
+
+func testRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var entries []string
+	for _, caller := range roleKeys {
+		sum := sha256.Sum256([]byte(caller.key))
+		entries = append(entries, string(caller.role)+":"+string(caller.role)+":"+hex.EncodeToString(sum[:]))
+	}
+
+	authenticator, err := middleware_auth.New(config.Auth{APIKeys: strings.Join(entries, ",")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	detailsClient, err := details.New("http://127.0.0.1:1", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return SetupRouter(stubSongs{}, stubGroups{}, stubLyrics{}, stubRevisions{}, stubTrash{}, stubAudit{},
+		authenticator, nil, detailsClient, health.New(time.Second))
+}
+
+// requestPath fills the parameters of a registered path.
+func requestPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			parts[i] = "1"
+		case strings.HasPrefix(part, "*"):
+			parts[i] = "index.html"
+		}
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func serve(router http.Handler, method string, path string, key string) int {
+	req := httptest.NewRequest(method, requestPath(path), nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w.Code
+}
+
+func TestPolicyCoversEveryRoute(t *testing.T) {
+	router := testRouter(t)
+
+	for _, route := range router.Routes() {
+		route := route
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			permission, ok := policy[route.Method+" "+route.Path]
+			if !ok {
+				t.Fatal("the route has no access policy")
+			}
+
+			status := serve(router, route.Method, route.Path, "")
+			if permission == middleware_rbac.Public {
+				if status == http.StatusUnauthorized || status == http.StatusForbidden {
+					t.Fatalf("public route answered %d without credentials", status)
+				}
+				return
+			}
+			if status != http.StatusUnauthorized {
+				t.Fatalf("answered %d without credentials, want 401", status)
+			}
+
+			lowest := -1
+			for i, caller := range roleKeys {
+				if middleware_rbac.Allows([]string{string(caller.role)}, permission) {
+					lowest = i
+					break
+				}
+			}
+			if lowest < 0 {
+				t.Fatalf("no role has the %q permission", permission)
+			}
+
+			if lowest > 0 {
+				below := roleKeys[lowest-1]
+				if status := serve(router, route.Method, route.Path, below.key); status != http.StatusForbidden {
+					t.Fatalf("answered %d to %s, want 403", status, below.role)
+				}
+			}
+
+			allowed := roleKeys[lowest]
+			if status := serve(router, route.Method, route.Path, allowed.key); status == http.StatusUnauthorized || status == http.StatusForbidden {
+				t.Fatalf("answered %d to %s, which has the %q permission", status, allowed.role, permission)
+			}
+		})
+	}
+}
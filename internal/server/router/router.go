@@ -2,7 +2,17 @@ package router
 
 import (
 	"test-case/internal/server/handlers"
+	middleware_auth "test-case/internal/server/middlewares/auth"
 	middleware_logger "test-case/internal/server/middlewares/logger"
+	middleware_metrics "test-case/internal/server/middlewares/metrics"
+	middleware_ratelimit "test-case/internal/server/middlewares/ratelimit"
+	middleware_rbac "test-case/internal/server/middlewares/rbac"
+	middleware_requestid "test-case/internal/server/middlewares/requestid"
+	middleware_requestinfo "test-case/internal/server/middlewares/requestinfo"
+	middleware_tracing "test-case/internal/server/middlewares/tracing"
+	"test-case/internal/utils/details"
+	"test-case/internal/utils/health"
+	"test-case/internal/utils/metrics"
 	"test-case/storage/repos"
 
 	_ "test-case/docs"
@@ -18,25 +28,103 @@ import (
 // @host localhost:8080
 // @BasePath /
 
-func SetupRouter(repos repos.SongRepository) *gin.Engine {
-	router := gin.Default()
+func SetupRouter(songRepo repos.SongRepository, groupRepo repos.GroupRepository,
+	lyricsRepo repos.LyricsRepository, revisionRepo repos.RevisionRepository, trashRepo repos.TrashRepository,
+	auditRepo repos.AuditRepository, authenticator *middleware_auth.Authenticator,
+	limiter *middleware_ratelimit.Limiter, detailsClient *details.Client, checker *health.Checker) *gin.Engine {
+	// RequestLogger replaces gin's own logger, which writes plain text.
+	router := gin.New()
+	router.Use(gin.Recovery())
+	// X-Forwarded-For is ignored until the app trusts its proxies, else
+	// any client could pick the IP it is limited and audited under.
+	router.SetTrustedProxies(nil)
 
-	handler := handlers.NewSongHandler(repos)
+	handler := handlers.NewSongHandler(songRepo, detailsClient)
+	groupHandler := handlers.NewGroupHandler(groupRepo)
+	lyricsHandler := handlers.NewLyricsHandler(lyricsRepo)
+	revisionHandler := handlers.NewRevisionHandler(revisionRepo)
+	trashHandler := handlers.NewTrashHandler(trashRepo)
+	auditHandler := handlers.NewAuditHandler(auditRepo)
+	healthHandler := handlers.NewHealthHandler(checker)
+	loggingHandler := handlers.NewLoggingHandler()
 
+	router.Use(middleware_metrics.Metrics())
+	router.Use(middleware_tracing.Tracing())
+	router.Use(middleware_requestid.RequestID())
 	router.Use(middleware_logger.RequestLogger())
+	router.Use(middleware_requestinfo.RequestInfo())
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/healthz", healthHandler.Liveness)
+	router.GET("/readyz", healthHandler.Readiness)
 
-	router.GET("/get-songs", handler.GetSongs)
-	router.GET("/get-song-text", handler.GetSongText)
-	router.DELETE("/delete-song", handler.DeleteSong)
-	router.POST("/update-song", handler.UpdateSong)
-	router.POST("/add-song", handler.AddSong)
+	api := router.Group("/")
+	// Limited by client IP ahead of authentication, which bounds guessing
+	// of API keys and tokens.
+	api.Use(limiter.Handler(middleware_ratelimit.GroupIP, nil))
+	api.Use(middleware_auth.Authenticate(authenticator))
+	api.Use(middleware_rbac.Authorize(policy, authenticator != nil))
+
+	read := api.Group("/", limiter.Handler(middleware_ratelimit.GroupRead, nil))
+	list := api.Group("/", limiter.Handler(middleware_ratelimit.GroupList, pageCost))
+	write := api.Group("/", limiter.Handler(middleware_ratelimit.GroupWrite, nil))
+
+	list.GET("/get-songs", handler.GetSongs)
+	read.GET("/get-song-text", handler.GetSongText)
+	write.DELETE("/delete-song", handler.DeleteSong)
+	write.POST("/update-song", handler.UpdateSong)
+	write.POST("/add-song", handler.AddSong)
+	write.POST("/import-songs", handler.ImportSongs)
+	list.GET("/export", handler.ExportSongs)
+
+	list.GET("/get-duplicates", handler.GetDuplicates)
+	write.POST("/merge-songs", handler.MergeSongs)
+	write.POST("/songs/:id/explicit", handler.SetExplicit)
+	write.POST("/normalize-text", handler.NormalizeText)
+
+	read.GET("/songs/:id/lyrics", lyricsHandler.GetLyrics)
+	write.POST("/songs/:id/lyrics", lyricsHandler.SetLyricsTranslation)
+	write.DELETE("/songs/:id/lyrics/:lang", lyricsHandler.DeleteLyricsTranslation)
+	read.GET("/songs/:id/lyrics/synced", lyricsHandler.GetSyncedLyrics)
+	read.GET("/songs/:id/lyrics/lrc", lyricsHandler.ExportLrc)
+	write.POST("/songs/:id/lyrics/lrc", lyricsHandler.ImportLrc)
+	read.GET("/songs/:id/lyrics/stats", lyricsHandler.GetLyricsStats)
+
+	read.GET("/songs/:id/revisions", revisionHandler.GetRevisions)
+	read.GET("/songs/:id/revisions/diff", revisionHandler.DiffRevisions)
+	write.POST("/songs/:id/revisions/:number/rollback", revisionHandler.RollbackSong)
+
+	read.GET("/get-group-aliases", groupHandler.GetGroupAliases)
+	write.POST("/add-group-alias", groupHandler.AddGroupAlias)
+	write.DELETE("/delete-group-alias", groupHandler.DeleteGroupAlias)
+	read.GET("/groups/:id/lyrics/stats", groupHandler.GetGroupLyricsStats)
+	write.DELETE("/delete-group", groupHandler.DeleteGroup)
+
+	read.GET("/trash", trashHandler.GetTrash)
+	write.DELETE("/trash", trashHandler.PurgeTrash)
+	write.POST("/trash/songs/:id/restore", trashHandler.RestoreSong)
+	write.POST("/trash/groups/:id/restore", trashHandler.RestoreGroup)
+	write.DELETE("/trash/songs/:id", trashHandler.PurgeSong)
+	write.DELETE("/trash/groups/:id", trashHandler.PurgeGroup)
+
+	list.GET("/audit", auditHandler.GetAudit)
+	list.GET("/audit/export", auditHandler.ExportAudit)
+
+	// Metrics name routes, tables and the details provider, so only
+	// callers with a key see them.
+	read.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	read.GET("/log-levels", loggingHandler.GetLogLevels)
+	write.POST("/log-levels", loggingHandler.SetLogLevels)
 
 	//ДЛЯ ДЕБАГА
 	router.GET("/info", func(c *gin.Context) {
 		c.JSON(200, gin.H{"releaseDate": "16.07.2006", "text": "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\nYou caught me under false pretenses\nHow long before you let me go?\n\nOoh\nYou set my soul alight\nOoh\nYou set my soul alight", "link": "https://www.youtube.com/watch?v=Xsp3_a-PMTw"})
 	})
 
+	if err := policy.Check(router.Routes()); err != nil {
+		panic(err)
+	}
+
 	return router
 }
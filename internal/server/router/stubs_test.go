@@ -0,0 +1,123 @@
+package router
+
+import (
+	"context"
+	"test-case/internal/models"
+	"test-case/internal/utils/lrc"
+	"test-case/internal/utils/lyrics"
+	"test-case/internal/utils/lyricstats"
+	"test-case/internal/utils/songimport"
+	"test-case/storage/repos"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The stub repositories find nothing, so every handler that gets past
+// authorization answers without a database.
+
+type stubSongs struct{}
+
+func (stubSongs) GetSongs(ctx context.Context, filterParams map[string]string, page string, limit string) ([]models.Song, error) {
+	return nil, nil
+}
+func (stubSongs) GetSongText(ctx context.Context, id string) (string, error) {
+	return "", gorm.ErrRecordNotFound
+}
+func (stubSongs) DeleteSong(ctx context.Context, id string) error { return gorm.ErrRecordNotFound }
+func (stubSongs) UpdateSong(ctx context.Context, updatedSong models.Song) error {
+	return gorm.ErrRecordNotFound
+}
+func (stubSongs) AddSong(ctx context.Context, newSong models.Song) (uint, error) {
+	return 0, gorm.ErrRecordNotFound
+}
+func (stubSongs) FindDuplicates(ctx context.Context, threshold float64) ([]models.DuplicateCandidate, error) {
+	return nil, nil
+}
+func (stubSongs) MergeSongs(ctx context.Context, keepId string, mergeId string) error {
+	return gorm.ErrRecordNotFound
+}
+func (stubSongs) DetectLanguages(ctx context.Context, all bool) (int, error) { return 0, nil }
+func (stubSongs) SetExplicitOverride(ctx context.Context, id string, override *bool) error {
+	return gorm.ErrRecordNotFound
+}
+func (stubSongs) FlagExplicit(ctx context.Context) (int, error)                { return 0, nil }
+func (stubSongs) NormalizeTexts(ctx context.Context, dryRun bool) (int, error) { return 0, nil }
+func (stubSongs) ImportSongs(ctx context.Context, reader songimport.Reader, options repos.ImportOptions) (models.ImportReport, error) {
+	return models.ImportReport{}, nil
+}
+func (stubSongs) ExportSongs(ctx context.Context, filterParams map[string]string, export func(models.Song) error) error {
+	return nil
+}
+
+type stubGroups struct{}
+
+func (stubGroups) GetAliases(ctx context.Context, groupId string) ([]models.GroupAlias, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (stubGroups) AddAlias(ctx context.Context, newAlias models.GroupAlias) (uint, error) {
+	return 0, gorm.ErrRecordNotFound
+}
+func (stubGroups) DeleteAlias(ctx context.Context, id string) error { return gorm.ErrRecordNotFound }
+func (stubGroups) GetLyricsStats(ctx context.Context, groupId string, top int) (models.GroupLyricsStats, error) {
+	return models.GroupLyricsStats{}, gorm.ErrRecordNotFound
+}
+func (stubGroups) DeleteGroup(ctx context.Context, id string) error { return gorm.ErrRecordNotFound }
+
+type stubLyrics struct{}
+
+func (stubLyrics) GetSections(ctx context.Context, songId string) ([]lyrics.Section, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (stubLyrics) GetSynced(ctx context.Context, songId string) (lrc.Lyrics, error) {
+	return lrc.Lyrics{}, gorm.ErrRecordNotFound
+}
+func (stubLyrics) SetSynced(ctx context.Context, songId string, synced lrc.Lyrics) error {
+	return gorm.ErrRecordNotFound
+}
+func (stubLyrics) GetTranslations(ctx context.Context, songId string) ([]models.Lyrics, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (stubLyrics) SetTranslation(ctx context.Context, translation models.Lyrics) error {
+	return gorm.ErrRecordNotFound
+}
+func (stubLyrics) DeleteTranslation(ctx context.Context, songId string, language string) error {
+	return gorm.ErrRecordNotFound
+}
+func (stubLyrics) GetStats(ctx context.Context, songId string, top int) (lyricstats.Stats, error) {
+	return lyricstats.Stats{}, gorm.ErrRecordNotFound
+}
+
+type stubRevisions struct{}
+
+func (stubRevisions) GetRevisions(ctx context.Context, songId string) ([]models.SongRevision, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (stubRevisions) DiffRevisions(ctx context.Context, songId string, from string, to string) (models.RevisionDiff, error) {
+	return models.RevisionDiff{}, gorm.ErrRecordNotFound
+}
+func (stubRevisions) RollbackSong(ctx context.Context, songId string, number string) (int, error) {
+	return 0, gorm.ErrRecordNotFound
+}
+
+type stubTrash struct{}
+
+func (stubTrash) GetTrash(ctx context.Context, page string, limit string) (models.Trash, error) {
+	return models.Trash{}, nil
+}
+func (stubTrash) RestoreSong(ctx context.Context, id string) error  { return gorm.ErrRecordNotFound }
+func (stubTrash) RestoreGroup(ctx context.Context, id string) error { return gorm.ErrRecordNotFound }
+func (stubTrash) PurgeSong(ctx context.Context, id string) error    { return gorm.ErrRecordNotFound }
+func (stubTrash) PurgeGroup(ctx context.Context, id string) error   { return gorm.ErrRecordNotFound }
+func (stubTrash) Purge(ctx context.Context, before time.Time) (models.PurgeResult, error) {
+	return models.PurgeResult{}, nil
+}
+
+type stubAudit struct{}
+
+func (stubAudit) GetAudit(ctx context.Context, filterParams map[string]string, page string, limit string) ([]models.AuditEntry, error) {
+	return nil, nil
+}
+func (stubAudit) ExportAudit(ctx context.Context, filterParams map[string]string, export func(models.AuditEntry) error) error {
+	return nil
+}
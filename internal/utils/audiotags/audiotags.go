@@ -0,0 +1,106 @@
+package audiotags
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Tags are the song details read from an audio file.
+type Tags struct {
+	Artist string
+	Title  string
+	Album  string
+	Date   string
+	Lyrics string
+}
+
+var (
+	ErrUnsupported = errors.New("unsupported audio format")
+	ErrNoTags      = errors.New("file has no tags")
+)
+
+// maxTag bounds a tag or comment block, which may carry cover art.
+const maxTag = 64 << 20
+
+// Read detects the container of an MP3, FLAC or OGG file and reads its
+// ID3v2 tag or Vorbis comments.
+func Read(src io.Reader) (Tags, error) {
+	reader := bufio.NewReader(src)
+
+	magic, err := reader.Peek(4)
+	if err != nil {
+		if err == io.EOF {
+			return Tags{}, ErrUnsupported
+		}
+		return Tags{}, err
+	}
+
+	var tags Tags
+	switch {
+	case bytes.HasPrefix(magic, []byte("ID3")):
+		tags, err = readID3(reader)
+	case bytes.Equal(magic, []byte("fLaC")):
+		tags, err = readFLAC(reader)
+	case bytes.Equal(magic, []byte("OggS")):
+		tags, err = readOgg(reader)
+	default:
+		return Tags{}, ErrUnsupported
+	}
+	if err != nil {
+		return Tags{}, err
+	}
+
+	tags.Artist = clean(tags.Artist)
+	tags.Title = clean(tags.Title)
+	tags.Album = clean(tags.Album)
+	tags.Date = clean(tags.Date)
+	tags.Lyrics = strings.TrimRight(tags.Lyrics, "\x00")
+
+	if tags == (Tags{}) {
+		return Tags{}, ErrNoTags
+	}
+
+	return tags, nil
+}
+
+// ReleaseDate turns a tag date into the DD.MM.YYYY form the library
+// uses. Dates with only a year or month are returned as they are.
+func (t Tags) ReleaseDate() string {
+	if len(t.Date) < len("2006-01-02") {
+		return t.Date
+	}
+
+	date, err := time.Parse("2006-01-02", t.Date[:len("2006-01-02")])
+	if err != nil {
+		return t.Date
+	}
+
+	return date.Format("02.01.2006")
+}
+
+// clean drops the padding and keeps the first of several values, which
+// ID3v2.4 separates with null characters.
+func clean(value string) string {
+	value, _, _ = strings.Cut(value, "\x00")
+	return strings.TrimSpace(value)
+}
+
+func readFull(src io.Reader, size int) ([]byte, error) {
+	if size < 0 || size > maxTag {
+		return nil, errors.New("tag is too large")
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(src, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,228 @@
+package audiotags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"unicode/utf16"
+)
+
+const (
+	id3Unsync         = 0x80
+	id3ExtendedHeader = 0x40
+
+	// Frame format flags of ID3v2.4.
+	frameUnsync      = 0x02
+	frameDataLength  = 0x01
+	frameCompressed  = 0x08
+	frameEncrypted   = 0x04
+	frame23Compress  = 0x80
+	frame23Encrypted = 0x40
+)
+
+// id3Frames maps the frame ids of all versions to the tag they fill.
+var id3Frames = map[string]string{
+	"TPE1": "artist", "TP1": "artist",
+	"TIT2": "title", "TT2": "title",
+	"TALB": "album", "TAL": "album",
+	"TDRC": "date", "TYER": "date", "TYE": "date",
+	"USLT": "lyrics", "ULT": "lyrics",
+}
+
+func readID3(src io.Reader) (Tags, error) {
+	header, err := readFull(src, 10)
+	if err != nil {
+		return Tags{}, err
+	}
+
+	version := header[3]
+	if version < 2 || version > 4 {
+		return Tags{}, ErrUnsupported
+	}
+	flags := header[5]
+
+	body, err := readFull(src, syncsafe(header[6:10]))
+	if err != nil {
+		return Tags{}, err
+	}
+
+	if flags&id3Unsync != 0 && version < 4 {
+		body = unsync(body)
+	}
+
+	if flags&id3ExtendedHeader != 0 && version > 2 {
+		if len(body) < 4 {
+			return Tags{}, errors.New("broken id3 extended header")
+		}
+		size := int(binary.BigEndian.Uint32(body[:4]))
+		if version == 4 {
+			size = syncsafe(body[:4])
+		} else {
+			// The v2.3 size does not count its own four bytes.
+			size += 4
+		}
+		if size > len(body) {
+			return Tags{}, errors.New("broken id3 extended header")
+		}
+		body = body[size:]
+	}
+
+	idSize, headerSize := 4, 10
+	if version == 2 {
+		idSize, headerSize = 3, 6
+	}
+
+	var tags Tags
+	for len(body) >= headerSize && body[0] != 0 {
+		id := string(body[:idSize])
+
+		var size int
+		var formatFlags byte
+		switch version {
+		case 2:
+			size = int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(body[4:8]))
+			formatFlags = body[9]
+		case 4:
+			size = syncsafe(body[4:8])
+			formatFlags = body[9]
+		}
+
+		if size > len(body)-headerSize {
+			break
+		}
+		data := body[headerSize : headerSize+size]
+		body = body[headerSize+size:]
+
+		field, ok := id3Frames[id]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case version == 3 && formatFlags&(frame23Compress|frame23Encrypted) != 0:
+			continue
+		case version == 4 && formatFlags&(frameCompressed|frameEncrypted) != 0:
+			continue
+		}
+		if version == 4 && formatFlags&frameDataLength != 0 {
+			if len(data) < 4 {
+				continue
+			}
+			data = data[4:]
+		}
+		if version == 4 && (formatFlags&frameUnsync != 0 || flags&id3Unsync != 0) {
+			data = unsync(data)
+		}
+
+		if field == "lyrics" {
+			if tags.Lyrics == "" {
+				tags.Lyrics = lyricsFrame(data)
+			}
+			continue
+		}
+
+		value := textFrame(data)
+		switch field {
+		case "artist":
+			tags.Artist = value
+		case "title":
+			tags.Title = value
+		case "album":
+			tags.Album = value
+		case "date":
+			tags.Date = value
+		}
+	}
+
+	return tags, nil
+}
+
+func syncsafe(data []byte) int {
+	return int(data[0]&0x7f)<<21 | int(data[1]&0x7f)<<14 | int(data[2]&0x7f)<<7 | int(data[3]&0x7f)
+}
+
+// unsync reverts the unsynchronisation scheme, which inserts a zero byte
+// after every 0xFF.
+func unsync(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xff, 0x00}, []byte{0xff})
+}
+
+func textFrame(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	return decode(data[0], data[1:])
+}
+
+// lyricsFrame reads an USLT frame: encoding, language, a null terminated
+// content descriptor and the lyrics.
+func lyricsFrame(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+
+	encoding := data[0]
+	rest := data[4:]
+
+	terminator := []byte{0}
+	if encoding == 1 || encoding == 2 {
+		terminator = []byte{0, 0}
+	}
+
+	for i := 0; i+len(terminator) <= len(rest); i += len(terminator) {
+		if bytes.Equal(rest[i:i+len(terminator)], terminator) {
+			return decode(encoding, rest[i+len(terminator):])
+		}
+	}
+
+	return ""
+}
+
+func decode(encoding byte, data []byte) string {
+	switch encoding {
+	case 1:
+		return decodeUTF16(data, true)
+	case 2:
+		return decodeUTF16(data, false)
+	case 3:
+		return string(data)
+	default:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+}
+
+// decodeUTF16 reads big endian text unless a byte order mark says
+// otherwise. With bom set, each null separated value may carry its own.
+func decodeUTF16(data []byte, bom bool) string {
+	var units []uint16
+	littleEndian := false
+
+	for i := 0; i+1 < len(data); i += 2 {
+		unit := binary.BigEndian.Uint16(data[i:])
+		if littleEndian {
+			unit = binary.LittleEndian.Uint16(data[i:])
+		}
+
+		if bom {
+			switch unit {
+			case 0xfeff:
+				continue
+			case 0xfffe:
+				littleEndian = !littleEndian
+				continue
+			}
+		}
+
+		units = append(units, unit)
+	}
+
+	return string(utf16.Decode(units))
+}
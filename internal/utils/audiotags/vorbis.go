@@ -0,0 +1,201 @@
+package audiotags
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+const flacVorbisComment = 4
+
+var errBrokenComment = errors.New("broken vorbis comment")
+
+func readFLAC(src io.Reader) (Tags, error) {
+	if _, err := readFull(src, 4); err != nil {
+		return Tags{}, err
+	}
+
+	for {
+		header, err := readFull(src, 4)
+		if err != nil {
+			return Tags{}, err
+		}
+
+		last := header[0]&0x80 != 0
+		kind := header[0] & 0x7f
+		size := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+
+		if kind == flacVorbisComment {
+			data, err := readFull(src, size)
+			if err != nil {
+				return Tags{}, err
+			}
+			return readComment(data)
+		}
+
+		if _, err := io.CopyN(io.Discard, src, int64(size)); err != nil {
+			return Tags{}, err
+		}
+
+		if last {
+			return Tags{}, nil
+		}
+	}
+}
+
+// readOgg finds the comment header among the first packets of an Ogg
+// Vorbis or Opus stream.
+func readOgg(src io.Reader) (Tags, error) {
+	packets := oggPackets{src: src}
+
+	for i := 0; i < 3; i++ {
+		packet, err := packets.next()
+		if err != nil {
+			if err == io.EOF {
+				return Tags{}, nil
+			}
+			return Tags{}, err
+		}
+
+		switch {
+		case bytes.HasPrefix(packet, []byte("\x03vorbis")):
+			return readComment(packet[7:])
+		case bytes.HasPrefix(packet, []byte("OpusTags")):
+			return readComment(packet[8:])
+		}
+	}
+
+	return Tags{}, nil
+}
+
+// oggPackets joins the segments of Ogg pages into packets. Only the
+// first logical stream matters for the comment header.
+type oggPackets struct {
+	src      io.Reader
+	segments []byte
+	page     []byte
+}
+
+func (p *oggPackets) next() ([]byte, error) {
+	var packet []byte
+
+	for {
+		if len(p.segments) == 0 {
+			if err := p.readPage(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		size := int(p.segments[0])
+		p.segments = p.segments[1:]
+		if size > len(p.page) {
+			return nil, errors.New("broken ogg page")
+		}
+
+		packet = append(packet, p.page[:size]...)
+		p.page = p.page[size:]
+		if len(packet) > maxTag {
+			return nil, errors.New("tag is too large")
+		}
+
+		if size < 255 {
+			return packet, nil
+		}
+	}
+}
+
+func (p *oggPackets) readPage() error {
+	header, err := readFull(p.src, 27)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return io.EOF
+		}
+		return err
+	}
+
+	if !bytes.Equal(header[:4], []byte("OggS")) {
+		return errors.New("broken ogg page")
+	}
+
+	p.segments, err = readFull(p.src, int(header[26]))
+	if err != nil {
+		return err
+	}
+
+	size := 0
+	for _, segment := range p.segments {
+		size += int(segment)
+	}
+
+	p.page, err = readFull(p.src, size)
+	return err
+}
+
+// readComment parses a Vorbis comment block: a vendor string and a list
+// of KEY=value fields, all lengths little endian.
+func readComment(data []byte) (Tags, error) {
+	readString := func() (string, error) {
+		if len(data) < 4 {
+			return "", errBrokenComment
+		}
+		size := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		if uint64(size) > uint64(len(data)) {
+			return "", errBrokenComment
+		}
+		value := string(data[:size])
+		data = data[size:]
+		return value, nil
+	}
+
+	if _, err := readString(); err != nil {
+		return Tags{}, err
+	}
+
+	if len(data) < 4 {
+		return Tags{}, errBrokenComment
+	}
+	count := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+
+	var tags Tags
+	for i := uint32(0); i < count; i++ {
+		field, err := readString()
+		if err != nil {
+			return Tags{}, err
+		}
+
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToUpper(key) {
+		case "ARTIST":
+			if tags.Artist == "" {
+				tags.Artist = value
+			}
+		case "TITLE":
+			if tags.Title == "" {
+				tags.Title = value
+			}
+		case "ALBUM":
+			if tags.Album == "" {
+				tags.Album = value
+			}
+		case "DATE":
+			if tags.Date == "" {
+				tags.Date = value
+			}
+		case "LYRICS", "UNSYNCEDLYRICS":
+			if tags.Lyrics == "" {
+				tags.Lyrics = value
+			}
+		}
+	}
+
+	return tags, nil
+}
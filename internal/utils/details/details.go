@@ -0,0 +1,153 @@
+package details
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"test-case/internal/utils/metrics"
+	"test-case/internal/utils/tracing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	calls = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "details",
+		Name:      "requests_total",
+		Help:      "Requests to the details provider by operation.",
+	}, []string{"operation"})
+
+	failures = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "details",
+		Name:      "failures_total",
+		Help:      "Failed requests to the details provider by operation.",
+	}, []string{"operation"})
+
+	latency = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "details",
+		Name:      "request_duration_seconds",
+		Help:      "Details provider latency by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+)
+
+// Details are what the external provider knows about a song.
+type Details struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Client asks the details provider at BASE_URL/info about songs.
+type Client struct {
+	infoURL string
+	http    *http.Client
+}
+
+func New(baseURL string, timeout time.Duration) (*Client, error) {
+	infoURL, err := url.JoinPath(baseURL, "/info")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		infoURL: infoURL,
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Fetch returns the details of a song.
+func (c *Client) Fetch(ctx context.Context, band string, song string) (details Details, err error) {
+	defer c.observe("fetch", time.Now(), &err)
+
+	params := url.Values{}
+	params.Add("group", band)
+	params.Add("song", song)
+
+	response, err := c.get(ctx, "fetch", c.infoURL+"?"+params.Encode())
+	if err != nil {
+		return Details{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return Details{}, fmt.Errorf("details provider answered %s", response.Status)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&details); err != nil {
+		return Details{}, fmt.Errorf("details provider: %w", err)
+	}
+
+	return details, nil
+}
+
+// Ping checks that the provider answers at all. Any response below 500
+// counts, the provider may well reject a request without a song.
+func (c *Client) Ping(ctx context.Context) (err error) {
+	defer c.observe("ping", time.Now(), &err)
+
+	response, err := c.get(ctx, "ping", c.infoURL)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("details provider answered %s", response.Status)
+	}
+
+	return nil
+}
+
+func (c *Client) observe(operation string, start time.Time, err *error) {
+	calls.WithLabelValues(operation).Inc()
+	latency.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		failures.WithLabelValues(operation).Inc()
+	}
+}
+
+// get sends the request in a client span of its own and passes the trace
+// context on to the provider.
+func (c *Client) get(ctx context.Context, operation string, target string) (*http.Response, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "details "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPRequestMethodKey.String(http.MethodGet)),
+	)
+	defer span.End()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.ServerAddress(request.URL.Hostname()))
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	if response.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, response.Status)
+	}
+
+	return response, nil
+}
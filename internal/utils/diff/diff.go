@@ -0,0 +1,68 @@
+package diff
+
+import "strings"
+
+const (
+	Equal  = "="
+	Insert = "+"
+	Delete = "-"
+)
+
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns a line-level diff that turns a into b, based on the
+// longest common subsequence of their lines.
+func Lines(a string, b string) []Line {
+	left := splitLines(a)
+	right := splitLines(b)
+
+	// lengths[i][j] is the LCS length of left[i:] and right[j:].
+	lengths := make([][]int, len(left)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	result := make([]Line, 0, max(len(left), len(right)))
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i] == right[j]:
+			result = append(result, Line{Op: Equal, Text: left[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			result = append(result, Line{Op: Delete, Text: left[i]})
+			i++
+		default:
+			result = append(result, Line{Op: Insert, Text: right[j]})
+			j++
+		}
+	}
+	for ; i < len(left); i++ {
+		result = append(result, Line{Op: Delete, Text: left[i]})
+	}
+	for ; j < len(right); j++ {
+		result = append(result, Line{Op: Insert, Text: right[j]})
+	}
+
+	return result
+}
+
+func splitLines(src string) []string {
+	if src == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+}
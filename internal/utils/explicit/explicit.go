@@ -0,0 +1,165 @@
+package explicit
+
+import (
+	"bufio"
+	"embed"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"test-case/internal/utils/normalize"
+)
+
+//go:embed wordlists/*.txt
+var bundledLists embed.FS
+
+// fallbackLanguage is used for lyrics in a language without a list.
+// Checking them against all lists at once would flag innocent words of
+// one language that are explicit in another.
+const fallbackLanguage = "en"
+
+// Analyzer flags lyrics that contain words from per-language lists.
+// Words are compared in lower case with their accents, so an explicit
+// word does not flag an ordinary one spelled without the accent.
+type Analyzer struct {
+	// words maps a language to exact words and prefixes.
+	words    map[string]map[string]struct{}
+	prefixes map[string][]string
+}
+
+type Result struct {
+	Explicit bool
+	Lines    []int
+}
+
+// Default uses the lists bundled with the service until Configure is
+// called.
+var Default = mustLoad(bundledLists, "wordlists")
+
+// Configure replaces the default analyzer with the bundled lists plus
+// the <language>.txt files found in dir, which extend the bundled list
+// of the same language.
+func Configure(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	analyzer, err := load(bundledLists, "wordlists")
+	if err != nil {
+		return err
+	}
+
+	if err := analyzer.addDir(os.DirFS(dir), "."); err != nil {
+		return err
+	}
+
+	Default = analyzer
+	return nil
+}
+
+// Analyze returns the 1-based numbers of the lines that contain an
+// explicit word. Lists of the given language are used, or the English
+// list when the language has none or was not detected.
+func (a *Analyzer) Analyze(text string, language string) Result {
+	if _, ok := a.words[language]; !ok {
+		language = fallbackLanguage
+	}
+
+	var result Result
+	for number, line := range strings.Split(text, "\n") {
+		for _, word := range normalize.AccentWords(line) {
+			if a.matches(language, word) {
+				result.Lines = append(result.Lines, number+1)
+				break
+			}
+		}
+	}
+
+	result.Explicit = len(result.Lines) > 0
+	return result
+}
+
+func (a *Analyzer) matches(language string, word string) bool {
+	if _, ok := a.words[language][word]; ok {
+		return true
+	}
+
+	for _, prefix := range a.prefixes[language] {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mustLoad(fsys fs.FS, dir string) *Analyzer {
+	analyzer, err := load(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return analyzer
+}
+
+func load(fsys fs.FS, dir string) (*Analyzer, error) {
+	analyzer := &Analyzer{
+		words:    make(map[string]map[string]struct{}),
+		prefixes: make(map[string][]string),
+	}
+
+	return analyzer, analyzer.addDir(fsys, dir)
+}
+
+func (a *Analyzer) addDir(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".txt" {
+			continue
+		}
+
+		file, err := fsys.Open(path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		language := strings.TrimSuffix(entry.Name(), ".txt")
+		err = a.addList(language, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *Analyzer) addList(language string, list io.Reader) error {
+	if _, ok := a.words[language]; !ok {
+		a.words[language] = make(map[string]struct{})
+	}
+
+	scanner := bufio.NewScanner(list)
+	for scanner.Scan() {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		if strings.HasSuffix(entry, "*") {
+			prefix := normalize.AccentKey(strings.TrimSuffix(entry, "*"))
+			a.prefixes[language] = append(a.prefixes[language], prefix)
+			continue
+		}
+
+		word := normalize.AccentKey(entry)
+		a.words[language][word] = struct{}{}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,82 @@
+package explicit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cleanLyrics hold ordinary words that share a stem or an unaccented
+// spelling with a listed word.
+var cleanLyrics = map[string]string{
+	"en": "Shitake in the cocktail bar\nDickens wrote of cockney scoundrels\nBitcoin buys a twattle of passes",
+	"es": "Un cono de helado en la mano\nLa disputa putativa se imputa\nVergüenza en el vergel de Cádiz",
+	"fr": "Un pique-nique sous le ciel\nLe bitume brille, la connaissance avance\nMerci pour la merveille",
+	"de": "Die Hürde vor dem Hurrikan\nEin Scheitel im Wind\nDer Fotograf und der Arsch der Welt",
+	"ru": "Сучок на сучковатом дереве\nНа сучке сидит птица\nУпотребление ребята оскорбление",
+	"uk": "Сучок на гілці\nКурвиметр лежить на столі\nРебята їбіс сук немає",
+}
+
+func TestCleanLyricsAreNotFlagged(t *testing.T) {
+	analyzer := mustLoad(bundledLists, "wordlists")
+
+	for language, text := range cleanLyrics {
+		t.Run(language, func(t *testing.T) {
+			if result := analyzer.Analyze(text, language); result.Explicit {
+				lines := strings.Split(text, "\n")
+				for _, number := range result.Lines {
+					t.Errorf("flagged line %d: %q", number, lines[number-1])
+				}
+			}
+		})
+	}
+}
+
+func TestExplicitLyricsAreFlagged(t *testing.T) {
+	analyzer := mustLoad(bundledLists, "wordlists")
+
+	tests := []struct {
+		language string
+		text     string
+		lines    []int
+	}{
+		{"en", "what a day\nwhat a shitty day\nmotherfuckers all around", []int{2, 3}},
+		{"es", "un cono de helado\n¡coño, qué frío!", []int{2}},
+		{"fr", "un pique-nique\nputain de pluie", []int{2}},
+		{"de", "so ein Scheißtag\nein schöner Tag", []int{1}},
+		{"ru", "тихий вечер\nну и сука же ты", []int{2}},
+		{"uk", "тихий вечір\nкурва мати", []int{2}},
+		// Undetermined lyrics are checked against the English list only.
+		{"", "coño\nshit", []int{2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			result := analyzer.Analyze(test.text, test.language)
+			if !reflect.DeepEqual(result.Lines, test.lines) {
+				t.Errorf("flagged lines %v, want %v", result.Lines, test.lines)
+			}
+		})
+	}
+}
+
+func TestConfigureExtendsBundledLists(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.txt"), []byte("# local\nfrak*\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundled := Default
+	t.Cleanup(func() { Default = bundled })
+
+	if err := Configure(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	result := Default.Analyze("frakking\nclean", "en")
+	if !reflect.DeepEqual(result.Lines, []int{1}) {
+		t.Errorf("flagged lines %v, want [1]", result.Lines)
+	}
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusFailed      = "failed"
+	StatusUnavailable = "unavailable"
+)
+
+// Check returns an error when the dependency it looks at is not usable.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one check.
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the readiness of the app with the result of every check.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Checker runs the readiness checks. Once shutdown has begun the app is
+// never ready again.
+type Checker struct {
+	checks   map[string]Check
+	timeout  time.Duration
+	stopping atomic.Bool
+}
+
+func New(timeout time.Duration) *Checker {
+	return &Checker{checks: make(map[string]Check), timeout: timeout}
+}
+
+func (c *Checker) Add(name string, check Check) {
+	c.checks[name] = check
+}
+
+// Shutdown makes every later readiness report fail.
+func (c *Checker) Shutdown() {
+	c.stopping.Store(true)
+}
+
+// Ready runs all checks at once, each bounded by the timeout.
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c.checks))}
+
+	if c.stopping.Load() {
+		report.Status = StatusUnavailable
+		report.Checks["shutdown"] = Result{Status: StatusFailed, Error: "server is shutting down", Duration: "0s"}
+		return report
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			result := Result{Status: StatusOK, Duration: time.Since(start).String()}
+			if err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = StatusUnavailable
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// Names reads the comma separated list of checks to run. An empty spec
+// selects all of the known ones.
+func Names(spec string, known []string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return known, nil
+	}
+
+	var names []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("unknown readiness check %q, known are %s", name, strings.Join(known, ", "))
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,196 @@
+package langdetect
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Undetermined is the BCP 47 tag returned when the language cannot be
+// told with enough confidence.
+const Undetermined = "und"
+
+// A text is undetermined below minConfidence. The posterior of naive
+// Bayes is close to 1 for any text of some length, so the confidence
+// also weighs in how much of the text is in lines of the same language.
+// A song with a chorus in another language stays above the threshold,
+// one that is half in each language falls below it.
+const (
+	minTrigrams   = 8
+	minConfidence = 0.75
+)
+
+//go:embed profiles/*.txt
+var profileFiles embed.FS
+
+type profile struct {
+	language string
+	counts   map[string]float64
+	total    float64
+}
+
+var profiles = loadProfiles()
+
+// Result is the detected language with its confidence, the posterior
+// probability scaled by the share of the text that agrees with it.
+type Result struct {
+	Language   string
+	Confidence float64
+}
+
+// Languages lists the BCP 47 tags the detector knows about.
+func Languages() []string {
+	result := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		result = append(result, p.language)
+	}
+	return result
+}
+
+// Detect compares the character trigrams of text against the bundled
+// profiles with a naive Bayes model, then detects every line on its own
+// to see how much of the text agrees. Texts that are too short, too
+// ambiguous or mixed are reported as Undetermined.
+func Detect(text string) Result {
+	trigrams := extract(text)
+	if total(trigrams) < minTrigrams {
+		return Result{Language: Undetermined}
+	}
+
+	scores := score(trigrams)
+	best := argmax(scores)
+
+	var sum float64
+	for i := range scores {
+		sum += math.Exp(scores[i] - scores[best])
+	}
+	confidence := 1 / sum * agreement(text, best)
+
+	if confidence < minConfidence {
+		return Result{Language: Undetermined, Confidence: confidence}
+	}
+
+	return Result{Language: profiles[best].language, Confidence: confidence}
+}
+
+// agreement is the share of trigrams in lines whose own best language
+// is the given profile.
+func agreement(text string, best int) float64 {
+	agreeing, all := 0, 0
+	for _, line := range strings.Split(text, "\n") {
+		trigrams := extract(line)
+		n := total(trigrams)
+		if n == 0 {
+			continue
+		}
+
+		all += n
+		if argmax(score(trigrams)) == best {
+			agreeing += n
+		}
+	}
+
+	if all == 0 {
+		return 0
+	}
+	return float64(agreeing) / float64(all)
+}
+
+// score is the log likelihood of the trigrams under every profile, with
+// add-one smoothing for trigrams a profile lacks.
+func score(trigrams map[string]int) []float64 {
+	scores := make([]float64, len(profiles))
+	for i, p := range profiles {
+		vocabulary := float64(len(p.counts))
+		for trigram, count := range trigrams {
+			probability := (p.counts[trigram] + 1) / (p.total + vocabulary)
+			scores[i] += float64(count) * math.Log(probability)
+		}
+	}
+	return scores
+}
+
+func argmax(scores []float64) int {
+	best := 0
+	for i := range scores {
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+func total(trigrams map[string]int) int {
+	n := 0
+	for _, count := range trigrams {
+		n += count
+	}
+	return n
+}
+
+func loadProfiles() []profile {
+	entries, err := profileFiles.ReadDir("profiles")
+	if err != nil {
+		panic(err)
+	}
+
+	result := make([]profile, 0, len(entries))
+	for _, entry := range entries {
+		data, err := profileFiles.ReadFile(path.Join("profiles", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		p := profile{
+			language: strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())),
+			counts:   make(map[string]float64),
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			trigram, value, ok := strings.Cut(line, " ")
+			count, err := strconv.ParseFloat(value, 64)
+			if !ok || err != nil {
+				panic(fmt.Sprintf("langdetect: bad line %q in %s", line, entry.Name()))
+			}
+
+			p.counts[trigram] = count
+			p.total += count
+		}
+
+		result = append(result, p)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].language < result[j].language
+	})
+
+	return result
+}
+
+// extract counts the trigrams inside every word, the way the profiles
+// were counted. Words are runs of letters, so apostrophes split them.
+func extract(text string) map[string]int {
+	result := make(map[string]int)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	for _, word := range words {
+		runes := []rune(word)
+		for i := 0; i+3 <= len(runes); i++ {
+			result[string(runes[i:i+3])]++
+		}
+	}
+
+	return result
+}
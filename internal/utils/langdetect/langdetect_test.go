@@ -0,0 +1,52 @@
+package langdetect
+
+import "testing"
+
+// lyrics are a couple of lines from traditional and public domain songs,
+// with es, pt and it and with ru and uk close enough to be confused.
+var lyrics = map[string]string{
+	"en": "Amazing grace, how sweet the sound\nThat saved a wretch like me\nI once was lost, but now am found\nWas blind, but now I see",
+	"de": "Stille Nacht, heilige Nacht\nAlles schläft, einsam wacht\nNur das traute hochheilige Paar\nHolder Knabe im lockigen Haar",
+	"fr": "Au clair de la lune, mon ami Pierrot\nPrête-moi ta plume pour écrire un mot\nMa chandelle est morte, je n'ai plus de feu\nOuvre-moi ta porte pour l'amour de Dieu",
+	"es": "De la Sierra Morena, cielito lindo, vienen bajando\nUn par de ojitos negros, cielito lindo, de contrabando\nAy, ay, ay, ay, canta y no llores\nPorque cantando se alegran, cielito lindo, los corazones",
+	"pt": "Ciranda, cirandinha, vamos todos cirandar\nVamos dar a meia volta, volta e meia vamos dar\nO anel que tu me deste era vidro e se quebrou\nO amor que tu me tinhas era pouco e se acabou",
+	"it": "Una mattina mi son svegliato, o bella ciao\nUna mattina mi son svegliato e ho trovato l'invasor\nO partigiano, portami via, che mi sento di morir",
+	"ru": "Ой, то не вечер, то не вечер\nМне малым-мало спалось\nМне малым-мало спалось\nОх, да во сне привиделось",
+	"uk": "Щедрик, щедрик, щедрівочка\nПрилетіла ластівочка\nСтала собі щебетати\nГосподаря викликати",
+}
+
+func TestDetectLyrics(t *testing.T) {
+	for language, text := range lyrics {
+		t.Run(language, func(t *testing.T) {
+			result := Detect(text)
+			if result.Language != language {
+				t.Errorf("Detect() = %s with %.2f, want %s", result.Language, result.Confidence, language)
+			}
+		})
+	}
+}
+
+func TestDetectUndetermined(t *testing.T) {
+	tests := map[string]string{
+		"too short":  "la la la",
+		"no letters": "1, 2, 3, 4!",
+		"ru and en":  "Ой, то не вечер, то не вечер\nМне малым-мало спалось\nAmazing grace, how sweet the sound\nThat saved a wretch like me",
+		"es and pt":  "De la Sierra Morena, cielito lindo, vienen bajando\nO anel que tu me deste era vidro e se quebrou",
+	}
+
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			if result := Detect(text); result.Language != Undetermined {
+				t.Errorf("Detect() = %s with %.2f, want %s", result.Language, result.Confidence, Undetermined)
+			}
+		})
+	}
+}
+
+func TestDetectChorusInAnotherLanguage(t *testing.T) {
+	text := lyrics["es"] + "\nAmazing grace, how sweet the sound"
+
+	if result := Detect(text); result.Language != "es" {
+		t.Errorf("Detect() = %s with %.2f, want es", result.Language, result.Confidence)
+	}
+}
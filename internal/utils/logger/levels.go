@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Levels are the global log level and the levels of single packages. A
+// package is named like the op its entries are logged with, up to the
+// function: storage.repos covers storage.repos.GetSongs, and storage
+// covers every storage package unless a longer name matches.
+type Levels struct {
+	Global   string            `json:"global"`
+	Packages map[string]string `json:"packages"`
+}
+
+type levelTable struct {
+	global   zerolog.Level
+	packages map[string]zerolog.Level
+}
+
+var levels atomic.Pointer[levelTable]
+
+// GetLevels returns the levels in effect.
+func GetLevels() Levels {
+	table := levels.Load()
+	if table == nil {
+		return Levels{Global: zerolog.GlobalLevel().String(), Packages: map[string]string{}}
+	}
+
+	current := Levels{Global: table.global.String(), Packages: make(map[string]string, len(table.packages))}
+	for name, level := range table.packages {
+		current.Packages[name] = level.String()
+	}
+
+	return current
+}
+
+// SetLevels replaces the global and all package levels at once, so it is
+// safe to call while requests are being logged.
+func SetLevels(l Levels) error {
+	global, err := parseLevel(l.Global)
+	if err != nil {
+		return err
+	}
+
+	table := &levelTable{global: global, packages: make(map[string]zerolog.Level, len(l.Packages))}
+	lowest := global
+	for name, value := range l.Packages {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.ContainsAny(name, " =,") {
+			return fmt.Errorf("invalid package name %q", name)
+		}
+
+		level, err := parseLevel(value)
+		if err != nil {
+			return fmt.Errorf("package %s: %w", name, err)
+		}
+
+		table.packages[name] = level
+		lowest = min(lowest, level)
+	}
+
+	// zerolog drops everything below its global level before any hook
+	// sees it, so it has to let through the most verbose package.
+	levels.Store(table)
+	zerolog.SetGlobalLevel(lowest)
+
+	return nil
+}
+
+// ParsePackageLevels reads package levels written as a comma separated
+// list of package=level.
+func ParsePackageLevels(spec string) (map[string]string, error) {
+	packages := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, level, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("package level %q is not in the package=level form", entry)
+		}
+		packages[strings.TrimSpace(name)] = strings.TrimSpace(level)
+	}
+
+	return packages, nil
+}
+
+func parseLevel(value string) (zerolog.Level, error) {
+	level, err := zerolog.ParseLevel(strings.ToLower(strings.TrimSpace(value)))
+	if err != nil || level == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("invalid log level %q, use one of %s", value, strings.Join(levelNames(), ", "))
+	}
+
+	return level, nil
+}
+
+func levelNames() []string {
+	names := []string{}
+	for level := zerolog.TraceLevel; level <= zerolog.PanicLevel; level++ {
+		names = append(names, level.String())
+	}
+	names = append(names, zerolog.Disabled.String())
+
+	return names
+}
+
+// levelHook drops entries below the level of their package. Entries whose
+// message is no op, as the request log, follow the global level.
+type levelHook struct{}
+
+func (levelHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	table := levels.Load()
+	if table == nil || len(table.packages) == 0 {
+		// zerolog's global level already is the global one.
+		return
+	}
+
+	if level < table.levelOf(msg) {
+		e.Discard()
+	}
+}
+
+// levelOf finds the level of the longest package name op starts with.
+func (t *levelTable) levelOf(op string) zerolog.Level {
+	for name := op; ; {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return t.global
+		}
+
+		name = name[:i]
+		if level, ok := t.packages[name]; ok {
+			return level
+		}
+	}
+}
@@ -1,36 +1,83 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"test-case/internal/config"
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var Logger zerolog.Logger
 
-func InitLogger(env string) {
-
-	if env == "local" {
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	} else {
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+// InitLogger writes JSON in prod and readable console output otherwise.
+// Levels are zerolog's level names, see SetLevels. Every entry passes the
+// redaction of redact.go, which masks the given secrets.
+func InitLogger(env string, cfg config.Logging, secrets ...string) error {
+	levels := Levels{Global: cfg.Level}
+	if levels.Global == "" {
+		levels.Global = zerolog.InfoLevel.String()
+		if env == "local" {
+			levels.Global = zerolog.DebugLevel.String()
+		}
 	}
 
-	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
-	output.FormatLevel = func(i interface{}) string {
-		return strings.ToUpper(fmt.Sprintf("| %-6s|", i))
+	var err error
+	if levels.Packages, err = ParsePackageLevels(cfg.Packages); err != nil {
+		return err
 	}
-	output.FormatFieldName = func(i interface{}) string {
-		return fmt.Sprintf("%s:", i)
+	if err := SetLevels(levels); err != nil {
+		return err
 	}
-	output.FormatFieldValue = func(i interface{}) string {
-		return strings.ToUpper(fmt.Sprintf("%s", i))
+
+	redactor := newRedactor(cfg.MaxFieldLength, secrets)
+
+	if env == "prod" {
+		Logger = zerolog.New(redactWriter{redactor: redactor, out: os.Stdout})
+	} else {
+		output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+		output.FormatLevel = func(i interface{}) string {
+			return strings.ToUpper(fmt.Sprintf("| %-6s|", i))
+		}
+		output.FormatFieldName = func(i interface{}) string {
+			return fmt.Sprintf("%s:", i)
+		}
+		Logger = zerolog.New(redactWriter{redactor: redactor, out: output})
 	}
 
-	Logger = zerolog.New(output).With().Timestamp().Logger()
+	Logger = Logger.With().Timestamp().Logger().Hook(levelHook{}).Hook(traceHook{})
+
+	// Contexts without a logger of their own fall back to the global one.
+	zerolog.DefaultContextLogger = &Logger
 
 	Logger.Info().Msg("Logger initialized")
+
+	return nil
+}
+
+// Ctx returns the logger of a request, which tags every entry with the
+// request id, or the global logger outside of requests.
+func Ctx(ctx context.Context) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+
+	return &Logger
+}
+
+// traceHook adds the ids of the current span to events logged with a
+// context, as the ones of a request logger.
+type traceHook struct{}
+
+func (traceHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	span := trace.SpanContextFromContext(e.GetCtx())
+	if !span.IsValid() {
+		return
+	}
+
+	e.Str("trace_id", span.TraceID().String()).Str("span_id", span.SpanID().String())
 }
@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys mark fields whose value is never logged. Keys are compared
+// in lower case with everything but letters removed, so X-API-Key and
+// api_key both match apikey.
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "apikey", "authorization", "cookie"}
+
+// credentialPattern finds credentials inside of text, as the password of
+// a connection string or an Authorization header in an error.
+var credentialPattern = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api[_-]?key)\s*[=:]\s*|bearer\s+)[^\s,;&"']+`)
+
+// ownFields are set by the logger itself. Secrets are masked in them too,
+// but they are never cut.
+var ownFields = map[string]bool{"level": true, "time": true, "message": true, "request_id": true, "trace_id": true, "span_id": true}
+
+// redactor masks secrets and cuts long strings in logged fields.
+type redactor struct {
+	maxLength int
+	secrets   []string
+}
+
+// minSecretLength is the shortest secret masked wherever it appears. A
+// short one, as the local database password "password", would match
+// ordinary words and corrupt the logs, so it is masked only where
+// credentialPattern finds it.
+const minSecretLength = 12
+
+func newRedactor(maxLength int, secrets []string) *redactor {
+	r := &redactor{maxLength: maxLength}
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+
+	return r
+}
+
+// value redacts a decoded JSON value, reporting whether it changed.
+func (r *redactor) value(key string, v interface{}) (interface{}, bool) {
+	if sensitiveKey(key) {
+		if v == nil || v == "" {
+			return v, false
+		}
+		return redacted, true
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		changed := false
+		for key, field := range v {
+			if redactedField, ok := r.value(key, field); ok {
+				v[key] = redactedField
+				changed = true
+			}
+		}
+		return v, changed
+	case []interface{}:
+		changed := false
+		for i, item := range v {
+			if redactedItem, ok := r.value(key, item); ok {
+				v[i] = redactedItem
+				changed = true
+			}
+		}
+		return v, changed
+	case string:
+		s := r.text(v, !ownFields[key])
+		return s, s != v
+	}
+
+	return v, false
+}
+
+func (r *redactor) text(s string, truncate bool) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	s = credentialPattern.ReplaceAllString(s, "${1}"+redacted)
+
+	if !truncate || r.maxLength <= 0 || len(s) <= r.maxLength {
+		return s
+	}
+
+	cut := r.maxLength
+	// A mask cut in half would look like part of the secret slipped out.
+	if i := strings.LastIndex(s[:cut], "["); i >= 0 && strings.HasPrefix(s[i:], redacted) {
+		cut = max(cut, i+len(redacted))
+	}
+	for cut < len(s) && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if cut >= len(s) {
+		return s
+	}
+
+	return fmt.Sprintf("%s... (%d bytes cut)", s[:cut], len(s)-cut)
+}
+
+func sensitiveKey(key string) bool {
+	key = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(key))
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactWriter redacts every entry before it reaches the output, so no
+// field, whether logged with Str, Interface or Err, is left out. Entries
+// with nothing to redact are written unchanged, the others are encoded
+// again, with their fields sorted.
+type redactWriter struct {
+	redactor *redactor
+	out      io.Writer
+}
+
+func (w redactWriter) Write(p []byte) (int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+
+	var entry map[string]interface{}
+	if err := decoder.Decode(&entry); err != nil {
+		return w.out.Write(p)
+	}
+
+	if _, changed := w.redactor.value("", entry); !changed {
+		return w.out.Write(p)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(entry); err != nil {
+		return 0, err
+	}
+
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,253 @@
+package lrc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrNoTimedLines = errors.New("lrc: no timed lines found")
+
+var (
+	timeTag     = regexp.MustCompile(`^(\d+):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+	metadataTag = regexp.MustCompile(`^([a-zA-Z#]+):(.*)$`)
+	wordTag     = regexp.MustCompile(`<(\d+:\d{1,2}(?:[.:]\d{1,3})?)>`)
+)
+
+// Word is a single word of enhanced LRC with its own start time.
+type Word struct {
+	Time int64  `json:"timeMs"`
+	Text string `json:"text"`
+}
+
+// Line is a lyric line that becomes active at Time milliseconds.
+// An empty Text marks a break between sections.
+type Line struct {
+	Time  int64  `json:"timeMs"`
+	Text  string `json:"text"`
+	Words []Word `json:"words,omitempty"`
+}
+
+type Lyrics struct {
+	Tags  map[string]string `json:"tags,omitempty"`
+	Lines []Line            `json:"lines"`
+}
+
+// Parse reads simple and enhanced (word-level) LRC. Lines carrying
+// several timestamps are repeated at each of them, the [offset:] tag is
+// applied to every time and the result is ordered by time.
+func Parse(src string) (Lyrics, error) {
+	result := Lyrics{Tags: make(map[string]string)}
+	var offset int64
+
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		rest := strings.TrimSpace(scanner.Text())
+
+		var times []int64
+		for strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				break
+			}
+			tag := rest[1:end]
+
+			if ms, ok := parseTime(tag); ok {
+				times = append(times, ms)
+				rest = rest[end+1:]
+				continue
+			}
+
+			if match := metadataTag.FindStringSubmatch(tag); match != nil && len(times) == 0 {
+				key := strings.ToLower(match[1])
+				value := strings.TrimSpace(match[2])
+				if key == "offset" {
+					parsed, err := strconv.ParseInt(strings.TrimPrefix(value, "+"), 10, 64)
+					if err != nil {
+						return Lyrics{}, fmt.Errorf("lrc: invalid offset %q", value)
+					}
+					offset = parsed
+				} else {
+					result.Tags[key] = value
+				}
+				rest = ""
+			}
+			break
+		}
+
+		if len(times) == 0 {
+			continue
+		}
+
+		for _, ms := range times {
+			text, words := parseWords(rest, ms)
+			result.Lines = append(result.Lines, Line{Time: ms, Text: text, Words: words})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Lyrics{}, err
+	}
+
+	if len(result.Lines) == 0 {
+		return Lyrics{}, ErrNoTimedLines
+	}
+
+	// A positive offset makes the lyrics appear sooner.
+	for i := range result.Lines {
+		result.Lines[i].Time = max(result.Lines[i].Time-offset, 0)
+		for j := range result.Lines[i].Words {
+			result.Lines[i].Words[j].Time = max(result.Lines[i].Words[j].Time-offset, 0)
+		}
+	}
+
+	sort.SliceStable(result.Lines, func(i, j int) bool {
+		return result.Lines[i].Time < result.Lines[j].Time
+	})
+
+	return result, nil
+}
+
+// Format writes lyrics back as LRC, using the enhanced word syntax for
+// lines that carry word timings.
+func Format(lyrics Lyrics) string {
+	var builder strings.Builder
+
+	keys := make([]string, 0, len(lyrics.Tags))
+	for key := range lyrics.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(&builder, "[%s:%s]\n", key, lyrics.Tags[key])
+	}
+
+	for _, line := range lyrics.Lines {
+		builder.WriteString("[" + formatTime(line.Time) + "]")
+
+		if len(line.Words) == 0 {
+			builder.WriteString(line.Text)
+		} else {
+			for i, word := range line.Words {
+				if i > 0 {
+					builder.WriteByte(' ')
+				}
+				builder.WriteString("<" + formatTime(word.Time) + ">" + word.Text)
+			}
+		}
+
+		builder.WriteByte('\n')
+	}
+
+	return builder.String()
+}
+
+// Text derives plain lyrics from the timed lines. Empty lines become a
+// single blank line separating sections.
+func (l Lyrics) Text() string {
+	var lines []string
+	blank := false
+
+	for _, line := range l.Lines {
+		if line.Text == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line.Text)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// At returns the index of the line active at the given playback offset
+// in milliseconds, or -1 before the first line starts.
+func (l Lyrics) At(offset int64) int {
+	return sort.Search(len(l.Lines), func(i int) bool {
+		return l.Lines[i].Time > offset
+	}) - 1
+}
+
+// Window returns the lines shown between from and to milliseconds,
+// including the line that is already active at from.
+func (l Lyrics) Window(from int64, to int64) []Line {
+	start := max(l.At(from), 0)
+
+	end := start
+	for end < len(l.Lines) && l.Lines[end].Time < to {
+		end++
+	}
+
+	return l.Lines[start:end]
+}
+
+func parseWords(src string, lineTime int64) (string, []Word) {
+	matches := wordTag.FindAllStringSubmatchIndex(src, -1)
+	if len(matches) == 0 {
+		return strings.TrimSpace(src), nil
+	}
+
+	words := make([]Word, 0, len(matches)+1)
+	texts := make([]string, 0, len(matches)+1)
+
+	// Text before the first word tag starts together with the line.
+	if prefix := strings.TrimSpace(src[:matches[0][0]]); prefix != "" {
+		words = append(words, Word{Time: lineTime, Text: prefix})
+		texts = append(texts, prefix)
+	}
+
+	for i, match := range matches {
+		end := len(src)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+
+		ms, _ := parseTime(src[match[2]:match[3]])
+		text := strings.TrimSpace(src[match[1]:end])
+		if text == "" {
+			continue
+		}
+
+		words = append(words, Word{Time: ms, Text: text})
+		texts = append(texts, text)
+	}
+
+	return strings.Join(texts, " "), words
+}
+
+func parseTime(src string) (int64, bool) {
+	match := timeTag.FindStringSubmatch(src)
+	if match == nil {
+		return 0, false
+	}
+
+	minutes, _ := strconv.ParseInt(match[1], 10, 64)
+	seconds, _ := strconv.ParseInt(match[2], 10, 64)
+
+	var fraction int64
+	switch len(match[3]) {
+	case 1:
+		fraction, _ = strconv.ParseInt(match[3], 10, 64)
+		fraction *= 100
+	case 2:
+		fraction, _ = strconv.ParseInt(match[3], 10, 64)
+		fraction *= 10
+	case 3:
+		fraction, _ = strconv.ParseInt(match[3], 10, 64)
+	}
+
+	return (minutes*60+seconds)*1000 + fraction, true
+}
+
+func formatTime(ms int64) string {
+	return fmt.Sprintf("%02d:%02d.%02d", ms/60000, ms/1000%60, ms%1000/10)
+}
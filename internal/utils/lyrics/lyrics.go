@@ -0,0 +1,242 @@
+package lyrics
+
+import (
+	"regexp"
+	"strings"
+	"test-case/internal/utils/normalize"
+)
+
+const (
+	Verse  = "verse"
+	Chorus = "chorus"
+	Bridge = "bridge"
+	Intro  = "intro"
+	Outro  = "outro"
+)
+
+// Section is a typed block of lyrics. A section that repeats an earlier
+// one keeps no text of its own and points at it through RepeatOf.
+type Section struct {
+	Kind     string `json:"kind"`
+	Label    string `json:"label,omitempty"`
+	Text     string `json:"text,omitempty"`
+	RepeatOf *int   `json:"repeatOf,omitempty"`
+}
+
+var (
+	bracketLabel = regexp.MustCompile(`^\[([^\]]+)\]$`)
+	plainLabel   = regexp.MustCompile(`^(?i)(pre-?chorus|chorus|refrain|hook|verse|bridge|intro|outro|припев|куплет|бридж|вступление|концовка|кода)\s*\d*\s*:?$`)
+)
+
+var kindWords = map[string]string{
+	"verse":      Verse,
+	"куплет":     Verse,
+	"chorus":     Chorus,
+	"prechorus":  Chorus,
+	"pre-chorus": Chorus,
+	"refrain":    Chorus,
+	"hook":       Chorus,
+	"припев":     Chorus,
+	"bridge":     Bridge,
+	"бридж":      Bridge,
+	"intro":      Intro,
+	"вступление": Intro,
+	"outro":      Outro,
+	"концовка":   Outro,
+	"кода":       Outro,
+}
+
+// Parse splits plain lyrics on blank lines into sections. Headings such
+// as "[Chorus]" or "Verse 2:" set the kind and label of the block below
+// them. Unlabelled blocks that occur more than once are treated as a
+// chorus, and every occurrence after the first becomes a reference, as
+// does a bare heading that repeats an earlier section of its kind.
+func Parse(src string) []Section {
+	blocks := splitBlocks(src)
+
+	counts := make(map[string]int)
+	for _, block := range blocks {
+		counts[normalize.Key(block.text)]++
+	}
+
+	sections := make([]Section, 0, len(blocks))
+	first := make(map[string]int)
+	lastByLabel := make(map[string]int)
+	lastByKind := make(map[string]int)
+
+	for _, block := range blocks {
+		section := Section{Kind: Verse, Label: block.label, Text: block.text}
+		if block.label != "" {
+			section.Kind = labelKind(block.label)
+		}
+
+		key := normalize.Key(block.text)
+		switch {
+		case key == "" && block.label != "":
+			position, ok := lastByLabel[normalize.Key(block.label)]
+			if !ok {
+				position, ok = lastByKind[section.Kind]
+			}
+			if ok {
+				section.Kind = sections[position].Kind
+				section.RepeatOf = &position
+			}
+		case key != "":
+			if position, ok := first[key]; ok {
+				section.Kind = sections[position].Kind
+				section.RepeatOf = &position
+				section.Text = ""
+			} else {
+				first[key] = len(sections)
+				if block.label == "" && counts[key] > 1 {
+					section.Kind = Chorus
+				}
+			}
+		}
+
+		if section.RepeatOf == nil && section.Text != "" {
+			lastByKind[section.Kind] = len(sections)
+			if block.label != "" {
+				lastByLabel[normalize.Key(block.label)] = len(sections)
+			}
+		}
+
+		sections = append(sections, section)
+	}
+
+	return sections
+}
+
+// Flatten turns sections back into plain text, expanding references
+// and leaving headings out.
+func Flatten(sections []Section) string {
+	parts := make([]string, 0, len(sections))
+
+	for _, section := range sections {
+		parts = append(parts, Resolve(sections, section).Text)
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// Resolve returns the section a reference points to, or the section
+// itself when it holds its own text.
+func Resolve(sections []Section, section Section) Section {
+	if section.RepeatOf != nil && *section.RepeatOf >= 0 && *section.RepeatOf < len(sections) {
+		return sections[*section.RepeatOf]
+	}
+
+	return section
+}
+
+type block struct {
+	label string
+	text  string
+}
+
+func splitBlocks(src string) []block {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+
+	var blocks []block
+	var current block
+	var lines []string
+
+	flush := func() {
+		if current.label == "" && len(lines) == 0 {
+			return
+		}
+		current.text = strings.Join(lines, "\n")
+		blocks = append(blocks, current)
+		current = block{}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+
+		if label, ok := parseLabel(line); ok {
+			flush()
+			current.label = label
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+	flush()
+
+	return blocks
+}
+
+func parseLabel(line string) (string, bool) {
+	if match := bracketLabel.FindStringSubmatch(line); match != nil {
+		return strings.TrimSpace(match[1]), true
+	}
+
+	if plainLabel.MatchString(line) {
+		return strings.TrimSpace(strings.TrimSuffix(line, ":")), true
+	}
+
+	return "", false
+}
+
+func labelKind(label string) string {
+	label = strings.ToLower(label)
+
+	for _, word := range strings.FieldsFunc(label, func(r rune) bool {
+		return r == ' ' || r == ':' || (r >= '0' && r <= '9')
+	}) {
+		if kind, ok := kindWords[word]; ok {
+			return kind
+		}
+	}
+
+	return Verse
+}
+
+// Align pairs the lines of two versions of the same lyrics for side by
+// side display. Blocks are matched first so that a translation with a
+// different number of lines in one stanza does not shift the rest; an
+// empty pair separates blocks.
+func Align(left string, right string) [][2]string {
+	leftBlocks := splitBlocks(left)
+	rightBlocks := splitBlocks(right)
+
+	var result [][2]string
+	for i := 0; i < max(len(leftBlocks), len(rightBlocks)); i++ {
+		if i > 0 {
+			result = append(result, [2]string{"", ""})
+		}
+
+		var leftLines, rightLines []string
+		if i < len(leftBlocks) {
+			leftLines = blockLines(leftBlocks[i])
+		}
+		if i < len(rightBlocks) {
+			rightLines = blockLines(rightBlocks[i])
+		}
+
+		for j := 0; j < max(len(leftLines), len(rightLines)); j++ {
+			var pair [2]string
+			if j < len(leftLines) {
+				pair[0] = leftLines[j]
+			}
+			if j < len(rightLines) {
+				pair[1] = rightLines[j]
+			}
+			result = append(result, pair)
+		}
+	}
+
+	return result
+}
+
+func blockLines(b block) []string {
+	if b.text == "" {
+		return nil
+	}
+	return strings.Split(b.text, "\n")
+}
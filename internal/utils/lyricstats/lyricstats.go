@@ -0,0 +1,173 @@
+package lyricstats
+
+import (
+	"bufio"
+	"embed"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"test-case/internal/utils/normalize"
+	"unicode"
+)
+
+// wordsPerMinute is an average silent reading speed.
+const wordsPerMinute = 200
+
+//go:embed stopwords/*.txt
+var stopwordFiles embed.FS
+
+var stopwords = loadStopwords()
+
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+type Stats struct {
+	Lines           int         `json:"lines"`
+	Words           int         `json:"words"`
+	UniqueWords     int         `json:"unique_words"`
+	RepetitionRatio float64     `json:"repetition_ratio"`
+	TopWords        []WordCount `json:"top_words"`
+	ReadingTime     float64     `json:"reading_time_seconds"`
+}
+
+// Collector accumulates statistics over one or more lyrics, so that a
+// band can be described by all of its songs at once.
+type Collector struct {
+	top      int
+	lines    int
+	repeated int
+	words    int
+	unique   map[string]struct{}
+	counts   map[string]int
+}
+
+func NewCollector(top int) *Collector {
+	return &Collector{
+		top:    top,
+		unique: make(map[string]struct{}),
+		counts: make(map[string]int),
+	}
+}
+
+// Compute returns the statistics of a single text.
+func Compute(text string, language string, top int) Stats {
+	collector := NewCollector(top)
+	collector.Add(text, language)
+	return collector.Stats()
+}
+
+// Add counts a text. A line is repeated when the same line, ignoring
+// case and punctuation, already occurred earlier in that text. Stop
+// words of the given language are left out of the top words; for an
+// unknown language every bundled list applies.
+func (c *Collector) Add(text string, language string) {
+	stop, ok := stopwords[language]
+	if !ok {
+		stop = stopwords[""]
+	}
+
+	seen := make(map[string]struct{})
+	for _, line := range strings.Split(text, "\n") {
+		key := normalize.Key(line)
+		if key == "" {
+			continue
+		}
+
+		c.lines++
+		if _, ok := seen[key]; ok {
+			c.repeated++
+		}
+		seen[key] = struct{}{}
+
+		for _, word := range Words(line) {
+			c.words++
+			c.unique[word] = struct{}{}
+			if _, ok := stop[word]; !ok {
+				c.counts[word]++
+			}
+		}
+	}
+}
+
+func (c *Collector) Stats() Stats {
+	result := Stats{
+		Lines:       c.lines,
+		Words:       c.words,
+		UniqueWords: len(c.unique),
+		TopWords:    make([]WordCount, 0, c.top),
+		ReadingTime: math.Round(float64(c.words)/wordsPerMinute*60*10) / 10,
+	}
+
+	if c.lines > 0 {
+		result.RepetitionRatio = float64(c.repeated) / float64(c.lines)
+	}
+
+	counts := make([]WordCount, 0, len(c.counts))
+	for word, count := range c.counts {
+		counts = append(counts, WordCount{Word: word, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Word < counts[j].Word
+	})
+
+	result.TopWords = append(result.TopWords, counts[:min(c.top, len(counts))]...)
+
+	return result
+}
+
+// Words splits text into lower-case words, keeping apostrophes inside
+// them so that contractions stay whole.
+func Words(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+
+	result := words[:0]
+	for _, word := range words {
+		word = strings.Trim(strings.ReplaceAll(word, "’", "'"), "'")
+		if word != "" {
+			result = append(result, word)
+		}
+	}
+
+	return result
+}
+
+// loadStopwords reads the bundled lists keyed by language. The empty
+// key holds the union of all of them.
+func loadStopwords() map[string]map[string]struct{} {
+	entries, err := stopwordFiles.ReadDir("stopwords")
+	if err != nil {
+		panic(err)
+	}
+
+	result := map[string]map[string]struct{}{"": {}}
+	for _, entry := range entries {
+		file, err := stopwordFiles.Open(path.Join("stopwords", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		language := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		result[language] = make(map[string]struct{})
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			word := strings.TrimSpace(scanner.Text())
+			if word == "" {
+				continue
+			}
+			result[language][word] = struct{}{}
+			result[""][word] = struct{}{}
+		}
+		file.Close()
+	}
+
+	return result
+}
@@ -0,0 +1,80 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Name prepares a name for storage: surrounding whitespace is trimmed,
+// inner whitespace collapsed to single spaces and the result is NFC.
+func Name(src string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(src), " "))
+}
+
+// NameKey folds a name so that lookups ignore case and accents while
+// keeping punctuation, so "Beyoncé " and "beyonce" share a key.
+func NameKey(src string) string {
+	return strings.ToLower(stripAccents(Name(src)))
+}
+
+// TitleKey folds a song title only in case and whitespace, so exact
+// duplicates are refused while "Señor" and "Senor" stay different songs.
+func TitleKey(src string) string {
+	return strings.ToLower(Name(src))
+}
+
+// Key folds a title or band name into a form suitable for matching:
+// lower case, accents and punctuation dropped and whitespace collapsed.
+func Key(src string) string {
+	return fold(stripAccents(src))
+}
+
+// AccentKey is Key keeping the accents, for words that accents tell
+// apart, as the Spanish "coño" and "cono".
+func AccentKey(src string) string {
+	return fold(norm.NFC.String(src))
+}
+
+func fold(src string) string {
+	var builder strings.Builder
+	space := false
+
+	for _, r := range strings.ToLower(src) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && builder.Len() > 0 {
+				builder.WriteByte(' ')
+			}
+			builder.WriteRune(r)
+			space = false
+			continue
+		}
+		space = true
+	}
+
+	return builder.String()
+}
+
+// Words splits text into matching keys of its individual words.
+func Words(src string) []string {
+	return strings.Fields(Key(src))
+}
+
+// AccentWords splits text into the AccentKey of its individual words.
+func AccentWords(src string) []string {
+	return strings.Fields(AccentKey(src))
+}
+
+func stripAccents(src string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	result, _, err := transform.String(t, src)
+	if err != nil {
+		return src
+	}
+
+	return result
+}
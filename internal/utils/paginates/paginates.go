@@ -1,26 +1,134 @@
 package paginates
 
 import (
+	"math"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 )
 
-func SongTextPaginate(src string, page int, limit int) []string {
-	parts := strings.Split(src, "\n\n")
+const (
+	TextModeBlock = "block"
+	TextModeLine  = "line"
+	TextModeChars = "chars"
+)
+
+// TextPage is one page of song text together with the totals a reader
+// needs to render navigation.
+type TextPage struct {
+	Parts         []string
+	TotalCouplets int
+	TotalPages    int
+}
+
+// SongTextPaginate pages song text by couplets, by lines or by a
+// character budget. In block and line modes limit is the number of
+// couplets or lines per page; in chars mode it is the budget itself and
+// every page holds as many whole lines as fit into it.
+func SongTextPaginate(src string, mode string, page int, limit int) TextPage {
+	blocks := SongTextBlocks(src)
+	result := TextPage{TotalCouplets: len(blocks)}
+
+	var parts []string
+	switch mode {
+	case TextModeLine:
+		parts = SongTextLines(src)
+	case TextModeChars:
+		parts = SongTextChunks(src, limit)
+		limit = 1
+	default:
+		parts = blocks
+	}
+
+	result.TotalPages = (len(parts) + limit - 1) / limit
+
+	// A page past the end is empty. It is checked before multiplying, as
+	// a huge page would overflow into a negative index.
+	if page < 1 || page > result.TotalPages {
+		result.Parts = []string{}
+		return result
+	}
 
 	startIndex := (page - 1) * limit
-	endIndex := startIndex + limit
+	endIndex := min(startIndex+limit, len(parts))
+
+	result.Parts = parts[startIndex:endIndex]
+
+	return result
+}
 
-	if startIndex > len(parts) {
-		startIndex = len(parts)
+// SongTextBlocks splits song text into couplets separated by one or more
+// blank lines.
+func SongTextBlocks(src string) []string {
+	var blocks []string
+	var lines []string
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(lines) > 0 {
+				blocks = append(blocks, strings.Join(lines, "\n"))
+				lines = nil
+			}
+			continue
+		}
+		lines = append(lines, line)
 	}
-	if endIndex > len(parts) {
-		endIndex = len(parts)
+	if len(lines) > 0 {
+		blocks = append(blocks, strings.Join(lines, "\n"))
 	}
 
-	return parts[startIndex:endIndex]
+	return blocks
+}
+
+// SongTextLines returns the non-blank lines of song text.
+func SongTextLines(src string) []string {
+	var lines []string
+
+	for _, block := range SongTextBlocks(src) {
+		lines = append(lines, strings.Split(block, "\n")...)
+	}
+
+	return lines
+}
+
+// SongTextChunks packs whole lines into chunks of at most budget
+// characters, keeping a blank line between couplets. A line longer than
+// the budget gets a chunk of its own.
+func SongTextChunks(src string, budget int) []string {
+	var chunks []string
+	var current []string
+	size := 0
+
+	for i, block := range SongTextBlocks(src) {
+		lines := strings.Split(block, "\n")
+		if i > 0 && len(current) > 0 {
+			lines = append([]string{""}, lines...)
+		}
+
+		for _, line := range lines {
+			length := utf8.RuneCountInString(line)
+			if len(current) > 0 && size+1+length > budget {
+				chunks = append(chunks, strings.TrimSpace(strings.Join(current, "\n")))
+				current = nil
+				size = 0
+			}
+			if len(current) == 0 && line == "" {
+				continue
+			}
+			if len(current) > 0 {
+				size++
+			}
+			current = append(current, line)
+			size += length
+		}
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.TrimSpace(strings.Join(current, "\n")))
+	}
+
+	return chunks
 }
 
 func SongPaginate(page string, limit string) func(db *gorm.DB) *gorm.DB {
@@ -38,6 +146,9 @@ func SongPaginate(page string, limit string) func(db *gorm.DB) *gorm.DB {
 			pageSize = 10
 		}
 
+		// Keeps the offset from overflowing for a huge page.
+		page = min(page, math.MaxInt32/pageSize)
+
 		offset := (page - 1) * pageSize
 		return db.Offset(offset).Limit(pageSize)
 	}
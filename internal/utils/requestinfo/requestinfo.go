@@ -0,0 +1,24 @@
+package requestinfo
+
+import "context"
+
+// Info describes who makes a change, why, and from which request. It
+// travels with the request context from the middlewares down to the
+// repositories.
+type Info struct {
+	Author    string
+	Reason    string
+	RequestId string
+	ClientIP  string
+}
+
+type contextKey struct{}
+
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
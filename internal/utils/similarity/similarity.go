@@ -0,0 +1,83 @@
+package similarity
+
+import (
+	"strings"
+	"test-case/internal/utils/normalize"
+)
+
+const shingleSize = 3
+
+// Ratio returns the normalized Levenshtein similarity of two strings
+// in the range [0, 1], where 1 means the keys are identical.
+func Ratio(a string, b string) float64 {
+	left := []rune(normalize.Key(a))
+	right := []rune(normalize.Key(b))
+
+	if len(left) == 0 && len(right) == 0 {
+		return 1
+	}
+
+	longest := max(len(left), len(right))
+
+	return 1 - float64(levenshtein(left, right))/float64(longest)
+}
+
+// Lyrics returns the Jaccard similarity of the word shingles of two
+// lyrics in the range [0, 1].
+func Lyrics(a string, b string) float64 {
+	left := shingles(normalize.Words(a))
+	right := shingles(normalize.Words(b))
+
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	common := 0
+	for shingle := range left {
+		if _, ok := right[shingle]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(left)+len(right)-common)
+}
+
+func shingles(words []string) map[string]struct{} {
+	result := make(map[string]struct{})
+
+	if len(words) < shingleSize {
+		if len(words) > 0 {
+			result[strings.Join(words, " ")] = struct{}{}
+		}
+		return result
+	}
+
+	for i := 0; i+shingleSize <= len(words); i++ {
+		result[strings.Join(words[i:i+shingleSize], " ")] = struct{}{}
+	}
+
+	return result
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,185 @@
+package songexport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"test-case/internal/models"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+)
+
+// Record is an exported song. Its field names match the columns accepted
+// by the import, so an export can be imported again.
+type Record struct {
+	Id          uint   `json:"id"`
+	Band        string `json:"band"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+	Language    string `json:"language"`
+	Explicit    bool   `json:"explicit"`
+}
+
+var header = []string{"id", "band", "song", "releaseDate", "text", "link", "language", "explicit"}
+
+func NewRecord(song models.Song) Record {
+	return Record{
+		Id:          song.Id,
+		Band:        song.Band,
+		Song:        song.Song,
+		ReleaseDate: song.ReleaseDate,
+		Text:        song.Text,
+		Link:        song.Link,
+		Language:    song.Language,
+		Explicit:    song.Explicit,
+	}
+}
+
+func (r Record) values() []string {
+	return []string{
+		strconv.FormatUint(uint64(r.Id), 10), r.Band, r.Song, r.ReleaseDate,
+		r.Text, r.Link, r.Language, strconv.FormatBool(r.Explicit),
+	}
+}
+
+// Writer writes records one at a time. Close completes the document but
+// does not close the underlying writer.
+type Writer interface {
+	Write(record Record) error
+	Close() error
+}
+
+func NewWriter(dst io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{writer: csv.NewWriter(dst)}, nil
+	case FormatJSON:
+		return &jsonWriter{dst: dst}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(dst)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(dst), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ContentType returns the MIME type of a format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "application/octet-stream"
+}
+
+// DetectFormat guesses the format from a file name, ignoring a .gz suffix.
+func DetectFormat(name string) string {
+	name = strings.TrimSuffix(strings.ToLower(name), ".gz")
+
+	for _, format := range []string{FormatNDJSON, FormatJSON, FormatCSV, FormatXLSX} {
+		if strings.HasSuffix(name, "."+format) {
+			return format
+		}
+	}
+	if strings.HasSuffix(name, ".jsonl") {
+		return FormatNDJSON
+	}
+
+	return ""
+}
+
+type csvWriter struct {
+	writer  *csv.Writer
+	started bool
+}
+
+func (w *csvWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	return w.writer.Write(header)
+}
+
+func (w *csvWriter) Write(record Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	return w.writer.Write(record.values())
+}
+
+func (w *csvWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonWriter struct {
+	dst   io.Writer
+	count int
+}
+
+func (w *jsonWriter) Write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n"
+	if w.count == 0 {
+		separator = "[\n"
+	}
+	w.count++
+
+	if _, err := io.WriteString(w.dst, separator); err != nil {
+		return err
+	}
+
+	_, err = w.dst.Write(data)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	closing := "\n]\n"
+	if w.count == 0 {
+		closing = "[]\n"
+	}
+
+	_, err := io.WriteString(w.dst, closing)
+	return err
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(record Record) error {
+	return w.encoder.Encode(record)
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,245 @@
+package songimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// Row is a single song of an import file. Number is the 1-based data row,
+// not counting the CSV header, and is what resuming refers to.
+type Row struct {
+	Number      int    `json:"-"`
+	Band        string `json:"band"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// RowError is a row that could not be read. The reader can continue with
+// the next row after it.
+type RowError struct {
+	Number int
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Number, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Reader streams rows of an import file. Next returns io.EOF after the
+// last row and a *RowError for a malformed row; any other error stops
+// the import.
+type Reader interface {
+	Next() (Row, error)
+}
+
+// DetectFormat guesses the format from a file name or content type,
+// returning an empty string when it cannot tell.
+func DetectFormat(name string) string {
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.Contains(name, "ndjson"), strings.Contains(name, "jsonl"):
+		return FormatNDJSON
+	case strings.Contains(name, "json"):
+		return FormatJSON
+	case strings.Contains(name, "csv"):
+		return FormatCSV
+	}
+
+	return ""
+}
+
+func NewReader(src io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(src)
+	case FormatJSON:
+		return newJSONReader(src)
+	case FormatNDJSON:
+		return &ndjsonReader{scanner: newScanner(src)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// columns maps accepted CSV header names to the row field they fill.
+var columns = map[string]string{
+	"band":         "band",
+	"group":        "band",
+	"song":         "song",
+	"title":        "song",
+	"releasedate":  "releaseDate",
+	"release_date": "releaseDate",
+	"text":         "text",
+	"lyrics":       "text",
+	"link":         "link",
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	fields  []string
+	counter int
+}
+
+func newCSVReader(src io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("csv file has no header")
+		}
+		return nil, err
+	}
+
+	fields := make([]string, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		fields[i] = columns[name]
+	}
+
+	return &csvReader{reader: reader, fields: fields}, nil
+}
+
+func (r *csvReader) Next() (Row, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return Row{}, io.EOF
+	}
+
+	r.counter++
+	row := Row{Number: r.counter}
+
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return row, &RowError{Number: r.counter, Err: parseErr.Err}
+		}
+		return row, err
+	}
+
+	for i, value := range record {
+		if i >= len(r.fields) {
+			break
+		}
+
+		switch r.fields[i] {
+		case "band":
+			row.Band = value
+		case "song":
+			row.Song = value
+		case "releaseDate":
+			row.ReleaseDate = value
+		case "text":
+			row.Text = value
+		case "link":
+			row.Link = value
+		}
+	}
+
+	return row, nil
+}
+
+// jsonReader decodes the elements of a top level array one at a time so
+// that large files are never held in memory.
+type jsonReader struct {
+	decoder *json.Decoder
+	counter int
+	done    bool
+}
+
+func newJSONReader(src io.Reader) (*jsonReader, error) {
+	decoder := json.NewDecoder(src)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("json import must be an array of songs")
+	}
+
+	return &jsonReader{decoder: decoder}, nil
+}
+
+func (r *jsonReader) Next() (Row, error) {
+	if r.done || !r.decoder.More() {
+		r.done = true
+		return Row{}, io.EOF
+	}
+
+	r.counter++
+
+	var raw json.RawMessage
+	if err := r.decoder.Decode(&raw); err != nil {
+		// The decoder cannot recover from broken syntax.
+		r.done = true
+		return Row{}, err
+	}
+
+	row := Row{}
+	if err := json.Unmarshal(raw, &row); err != nil {
+		return Row{Number: r.counter}, &RowError{Number: r.counter, Err: err}
+	}
+	row.Number = r.counter
+
+	return row, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	counter int
+}
+
+// maxLine bounds a single NDJSON line, which holds a whole song text.
+const maxLine = 4 << 20
+
+func newScanner(src io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	return scanner
+}
+
+func (r *ndjsonReader) Next() (Row, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		r.counter++
+
+		row := Row{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			return Row{Number: r.counter}, &RowError{Number: r.counter, Err: err}
+		}
+		row.Number = r.counter
+
+		return row, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Row{}, err
+	}
+
+	return Row{}, io.EOF
+}
@@ -0,0 +1,168 @@
+package textnorm
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Step names a single normalization applied to lyrics on ingest.
+type Step string
+
+const (
+	Newlines   Step = "newlines"
+	Entities   Step = "entities"
+	NFC        Step = "nfc"
+	Quotes     Step = "quotes"
+	Whitespace Step = "whitespace"
+)
+
+// order is the order steps run in regardless of how they were
+// configured: entities are decoded before whitespace cleanup so that
+// "&nbsp;" is collapsed like any other space.
+var order = []Step{Entities, Newlines, NFC, Quotes, Whitespace}
+
+var steps = map[Step]func(string) string{
+	Newlines:   normalizeNewlines,
+	Entities:   decodeEntities,
+	NFC:        norm.NFC.String,
+	Quotes:     straightenQuotes,
+	Whitespace: cleanWhitespace,
+}
+
+// Pipeline normalizes lyrics text with a set of enabled steps.
+type Pipeline struct {
+	steps []Step
+}
+
+type StepResult struct {
+	Step    Step `json:"step"`
+	Changed bool `json:"changed"`
+}
+
+type Result struct {
+	Text    string       `json:"text"`
+	Changed bool         `json:"changed"`
+	Steps   []StepResult `json:"steps"`
+}
+
+// Default runs all steps until Configure is called.
+var Default = New(order)
+
+// Configure replaces the default pipeline with the comma separated steps
+// in spec. An empty spec keeps all steps, "none" disables normalization.
+func Configure(spec string) error {
+	parsed, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	Default = New(parsed)
+	return nil
+}
+
+// Parse reads a comma separated list of step names.
+func Parse(spec string) ([]Step, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "":
+		return order, nil
+	case "none":
+		return nil, nil
+	}
+
+	var parsed []Step
+	for _, name := range strings.Split(spec, ",") {
+		step := Step(strings.ToLower(strings.TrimSpace(name)))
+		if _, ok := steps[step]; !ok {
+			return nil, fmt.Errorf("unknown normalization step %q", name)
+		}
+		parsed = append(parsed, step)
+	}
+
+	return parsed, nil
+}
+
+func New(enabled []Step) *Pipeline {
+	pipeline := &Pipeline{}
+	for _, step := range order {
+		for _, candidate := range enabled {
+			if candidate == step {
+				pipeline.steps = append(pipeline.steps, step)
+				break
+			}
+		}
+	}
+
+	return pipeline
+}
+
+func (p *Pipeline) Steps() []Step {
+	return p.steps
+}
+
+// Apply returns the normalized text.
+func (p *Pipeline) Apply(text string) string {
+	return p.Run(text).Text
+}
+
+// Run normalizes text and reports which steps changed it.
+func (p *Pipeline) Run(text string) Result {
+	result := Result{Text: text, Steps: make([]StepResult, 0, len(p.steps))}
+
+	for _, step := range p.steps {
+		normalized := steps[step](result.Text)
+
+		changed := normalized != result.Text
+		result.Steps = append(result.Steps, StepResult{Step: step, Changed: changed})
+		result.Changed = result.Changed || changed
+		result.Text = normalized
+	}
+
+	return result
+}
+
+var newlineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\u2028", "\n", "\u2029", "\n\n")
+
+func normalizeNewlines(text string) string {
+	return newlineReplacer.Replace(text)
+}
+
+// decodeEntities also handles entities escaped twice, as in "&amp;#39;".
+func decodeEntities(text string) string {
+	for i := 0; i < 2 && strings.Contains(text, "&"); i++ {
+		text = html.UnescapeString(text)
+	}
+
+	return text
+}
+
+var quoteReplacer = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201a", "'", "\u201b", "'", "\u2032", "'",
+	"\u201c", `"`, "\u201d", `"`, "\u201e", `"`, "\u201f", `"`, "\u2033", `"`,
+)
+
+func straightenQuotes(text string) string {
+	return quoteReplacer.Replace(text)
+}
+
+var (
+	spaceRun   = regexp.MustCompile(`[\t\f\v \p{Zs}]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// cleanWhitespace collapses spaces within lines, trims every line and
+// keeps at most one blank line between couplets, which is what
+// paginates expects.
+func cleanWhitespace(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRun.ReplaceAllString(line, " "))
+	}
+
+	text = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.Trim(text, "\n")
+}
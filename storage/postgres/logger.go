@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"test-case/internal/utils/logger"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQuery is how long a query may take before it is logged as a
+// warning.
+const slowQuery = 200 * time.Millisecond
+
+// queryLogger hands GORM's logging to the request logger, so statements
+// pass the redaction of long values and secrets, carry the request id
+// and follow the level of the storage.postgres package. Every statement
+// is logged at debug level.
+type queryLogger struct{}
+
+func (l queryLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+func (queryLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	logger.Ctx(ctx).Info().Msg(fmt.Sprintf(msg, data...))
+}
+
+func (queryLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	logger.Ctx(ctx).Warn().Msg(fmt.Sprintf(msg, data...))
+}
+
+func (queryLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	logger.Ctx(ctx).Error().Msg(fmt.Sprintf(msg, data...))
+}
+
+func (queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	const op = "storage.postgres.Query"
+
+	elapsed := time.Since(begin)
+	l := logger.Ctx(ctx)
+
+	event := l.Debug()
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		event = l.Info().Interface("Error occured: ", err.Error())
+	case elapsed > slowQuery:
+		event = l.Warn().Bool("slow", true)
+	}
+	if !event.Enabled() {
+		return
+	}
+
+	sql, rows := fc()
+	event.Str("sql", sql).Int64("rows", rows).Dur("duration", elapsed).Msg(op)
+}
@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"fmt"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/normalize"
+
+	"gorm.io/gorm"
+)
+
+// mergeGroupNames is a one-off migration for databases created before
+// group names were normalized. It fills name_key and merges groups that
+// collapse to the same key into the oldest one, so that AutoMigrate can
+// create the unique index afterwards.
+func mergeGroupNames(db *gorm.DB) error {
+	const op = "storage.postgres.mergeGroupNames"
+
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.Group{}) || migrator.HasColumn(&models.Group{}, "name_key") {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("ALTER TABLE groups ADD COLUMN name_key text").Error; err != nil {
+			return err
+		}
+
+		// Plain SQL, as the models may have columns the old table lacks.
+		var groups []models.Group
+		if err := tx.Raw("SELECT id, name FROM groups ORDER BY id").Scan(&groups).Error; err != nil {
+			return err
+		}
+
+		canonical := make(map[string]uint)
+		for _, group := range groups {
+			key := normalize.NameKey(group.Name)
+
+			keptId, ok := canonical[key]
+			if !ok {
+				canonical[key] = group.Id
+				if err := tx.Exec("UPDATE groups SET name = ?, name_key = ? WHERE id = ?",
+					normalize.Name(group.Name), key, group.Id).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.Exec("UPDATE songs SET group_id = ? WHERE group_id = ?", keptId, group.Id).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM groups WHERE id = ?", group.Id).Error; err != nil {
+				return err
+			}
+
+			logger.Logger.Info().Uint("kept", keptId).Uint("merged", group.Id).
+				Str("name", group.Name).Msg("Groups merged")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// dropGroupNameIndex drops the unique index on group names from before
+// soft deletes, so that AutoMigrate replaces it with one that ignores
+// deleted groups and a trashed band does not block its name.
+func dropGroupNameIndex(db *gorm.DB) error {
+	const op = "storage.postgres.dropGroupNameIndex"
+
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.Group{}) || !migrator.HasIndex(&models.Group{}, "group_name_key_index") {
+		return nil
+	}
+
+	if err := migrator.DropIndex(&models.Group{}, "group_name_key_index"); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// protectAuditLog makes the audit log append-only: the database itself
+// refuses to change or delete an entry.
+func protectAuditLog(db *gorm.DB) error {
+	const op = "storage.postgres.protectAuditLog"
+
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_log is append-only';
+		END;
+		$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log`,
+		`CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+		FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only()`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// backfillOriginalLyrics creates the original lyrics entry for songs
+// stored before the lyrics table existed. Their language is unknown.
+func backfillOriginalLyrics(db *gorm.DB) error {
+	const op = "storage.postgres.backfillOriginalLyrics"
+
+	err := db.Exec("INSERT INTO lyrics (song_id, language, kind, text) "+
+		"SELECT id, ?, ?, text FROM songs", "und", models.LyricsOriginal).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// backfillRevisions records the current state of songs stored before
+// revision history existed as their first revision.
+func backfillRevisions(db *gorm.DB) error {
+	const op = "storage.postgres.backfillRevisions"
+
+	err := db.Exec("INSERT INTO song_revisions " +
+		"(song_id, number, author, reason, created_at, band, song, release_date, text, link) " +
+		"SELECT s.id, 1, '', 'Initial revision', now(), COALESCE(g.name, ''), s.song, s.release_date, s.text, s.link " +
+		"FROM songs s LEFT JOIN groups g ON g.id = s.group_id").Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// syncSongIds moves the id sequence of songs past the highest id. Songs
+// used to be numbered by the service as MAX(id) + 1, which left the
+// sequence behind, and it is never moved back.
+func syncSongIds(db *gorm.DB) error {
+	const op = "storage.postgres.syncSongIds"
+
+	var sequence string
+	if err := db.Raw("SELECT pg_get_serial_sequence('songs', 'id')").Scan(&sequence).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := db.Exec(fmt.Sprintf(`SELECT setval(?::regclass, MAX(id)) FROM songs
+		HAVING MAX(id) > (SELECT CASE WHEN is_called THEN last_value ELSE last_value - 1 END FROM %s)`,
+		sequence), sequence).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -1,8 +1,10 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sync/atomic"
 	"test-case/internal/config"
 	"test-case/internal/models"
 
@@ -12,8 +14,12 @@ import (
 
 type Database struct {
 	Database *gorm.DB
+
+	migrated atomic.Bool
 }
 
+// New connects to the database. The schema is brought up to date by
+// Migrate.
 func New(config config.Config) (*Database, error) {
 	const op = "storage.postgres.New"
 
@@ -27,22 +33,86 @@ func New(config config.Config) (*Database, error) {
 		dbUrl = os.Getenv("DATABASE_URL")
 	}
 
-	db, err := gorm.Open(postgres.Open(dbUrl), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dbUrl), &gorm.Config{Logger: queryLogger{}})
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if config.Env != "prod" {
-		db = db.Debug()
+	if err := registerMetrics(db); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err := db.AutoMigrate(&models.Group{}, &models.Song{}); err != nil {
+	if err := registerTracing(db); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return &Database{Database: db}, nil
 }
 
+// Migrate runs the schema migrations and the data backfills that come
+// with them.
+func (database *Database) Migrate() error {
+	const op = "storage.postgres.Migrate"
+
+	db := database.Database
+
+	if err := mergeGroupNames(db); err != nil {
+		return err
+	}
+
+	if err := dropGroupNameIndex(db); err != nil {
+		return err
+	}
+
+	newLyricsTable := !db.Migrator().HasTable(&models.Lyrics{})
+	newRevisionsTable := !db.Migrator().HasTable(&models.SongRevision{})
+
+	if err := db.AutoMigrate(&models.Group{}, &models.GroupAlias{}, &models.Song{}, &models.LyricsSection{},
+		&models.SyncedLine{}, &models.Lyrics{}, &models.SongRevision{}, &models.SongMerge{},
+		&models.ScannedFile{}, &models.AuditEntry{}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := protectAuditLog(db); err != nil {
+		return err
+	}
+
+	if err := syncSongIds(db); err != nil {
+		return err
+	}
+
+	if newLyricsTable {
+		if err := backfillOriginalLyrics(db); err != nil {
+			return err
+		}
+	}
+
+	if newRevisionsTable {
+		if err := backfillRevisions(db); err != nil {
+			return err
+		}
+	}
+
+	database.migrated.Store(true)
+
+	return nil
+}
+
+// Migrated reports whether Migrate has finished.
+func (database *Database) Migrated() bool {
+	return database.migrated.Load()
+}
+
+// Ping checks the connection to the database.
+func (database *Database) Ping(ctx context.Context) error {
+	storage, err := database.Database.DB()
+	if err != nil {
+		return err
+	}
+
+	return storage.PingContext(ctx)
+}
+
 func (db *Database) Stop() error {
 	const op = "storage.postgres.Stop"
 
@@ -0,0 +1,34 @@
+package repos
+
+import (
+	"test-case/internal/models"
+	"test-case/internal/utils/explicit"
+	"test-case/internal/utils/langdetect"
+)
+
+// analyzeSong fills the fields derived from the lyrics of a song. It
+// runs whenever the text is written.
+func analyzeSong(song *models.Song) {
+	detectLanguage(song)
+	flagExplicit(song)
+}
+
+func detectLanguage(song *models.Song) {
+	result := langdetect.Detect(song.Text)
+
+	song.Language = result.Language
+	song.LanguageConfidence = result.Confidence
+}
+
+// flagExplicit runs after language detection so that the word lists of
+// the detected language are used. A manual override set by an editor
+// wins over the analyzer.
+func flagExplicit(song *models.Song) {
+	result := explicit.Default.Analyze(song.Text, song.Language)
+
+	song.ExplicitLines = result.Lines
+	song.Explicit = result.Explicit
+	if song.ExplicitOverride != nil {
+		song.Explicit = *song.ExplicitOverride
+	}
+}
@@ -0,0 +1,156 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/paginates"
+	"test-case/internal/utils/requestinfo"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	GetAudit(ctx context.Context, filterParams map[string]string, page string, limit string) ([]models.AuditEntry, error)
+	ExportAudit(ctx context.Context, filterParams map[string]string, export func(models.AuditEntry) error) error
+}
+
+type auditRepo struct {
+	database *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepo{database: db}
+}
+
+func (r *auditRepo) GetAudit(ctx context.Context, filterParams map[string]string, page string, limit string) ([]models.AuditEntry, error) {
+	const op = "storage.repos.GetAudit"
+
+	query, err := r.filterAudit(filterParams)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return nil, err
+	}
+
+	entries := []models.AuditEntry{}
+	result := query.WithContext(ctx).Order("id desc").Scopes(paginates.SongPaginate(page, limit)).Find(&entries)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return nil, result.Error
+	}
+
+	return entries, nil
+}
+
+// ExportAudit passes every matching entry to export, oldest first, reading
+// them from a database cursor.
+func (r *auditRepo) ExportAudit(ctx context.Context, filterParams map[string]string, export func(models.AuditEntry) error) error {
+	const op = "storage.repos.ExportAudit"
+
+	query, err := r.filterAudit(filterParams)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	query = query.WithContext(ctx).Order("id asc")
+	rows, err := query.Rows()
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		if err := query.ScanRows(rows, &entry); err != nil {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+			return err
+		}
+
+		if err := export(entry); err != nil {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// filterAudit builds the query for the actor, action, entity, entityId
+// and from/to (RFC 3339) filters.
+func (r *auditRepo) filterAudit(filterParams map[string]string) (*gorm.DB, error) {
+	query := r.database.Model(&models.AuditEntry{})
+
+	if filterParams["actor"] != "" {
+		query = query.Where("actor = ?", filterParams["actor"])
+	}
+	if filterParams["action"] != "" {
+		query = query.Where("action = ?", filterParams["action"])
+	}
+	if filterParams["entity"] != "" {
+		query = query.Where("entity = ?", filterParams["entity"])
+	}
+	if filterParams["entityId"] != "" {
+		query = query.Where("entity_id = ?", filterParams["entityId"])
+	}
+	if filterParams["from"] != "" {
+		from, err := time.Parse(time.RFC3339, filterParams["from"])
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if filterParams["to"] != "" {
+		to, err := time.Parse(time.RFC3339, filterParams["to"])
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("created_at < ?", to)
+	}
+
+	return query, nil
+}
+
+// writeAudit appends an audit entry inside the transaction of the write it
+// describes. before and after are stored as JSON, nil for a side that
+// does not exist.
+func writeAudit(ctx context.Context, tx *gorm.DB, action string, entity string, id uint, before any, after any) error {
+	entry, err := newAuditEntry(ctx, action, entity, id, before, after)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// newAuditEntry builds the audit entry writeAudit stores.
+func newAuditEntry(ctx context.Context, action string, entity string, id uint, before any, after any) (models.AuditEntry, error) {
+	info := requestinfo.FromContext(ctx)
+
+	entry := models.AuditEntry{
+		Actor:     info.Author,
+		Action:    action,
+		Entity:    entity,
+		EntityId:  id,
+		Reason:    info.Reason,
+		RequestId: info.RequestId,
+		ClientIP:  info.ClientIP,
+	}
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return models.AuditEntry{}, err
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return models.AuditEntry{}, err
+		}
+	}
+
+	return entry, nil
+}
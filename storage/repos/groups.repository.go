@@ -0,0 +1,252 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/lyricstats"
+	"test-case/internal/utils/normalize"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GroupRepository interface {
+	GetAliases(ctx context.Context, groupId string) ([]models.GroupAlias, error)
+	AddAlias(ctx context.Context, newAlias models.GroupAlias) (uint, error)
+	DeleteAlias(ctx context.Context, id string) error
+	GetLyricsStats(ctx context.Context, groupId string, top int) (models.GroupLyricsStats, error)
+	DeleteGroup(ctx context.Context, id string) error
+}
+
+var ErrAliasTaken = errors.New("alias is already used by a group")
+
+type groupRepo struct {
+	database *gorm.DB
+}
+
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepo{database: db}
+}
+
+func (r *groupRepo) GetAliases(ctx context.Context, groupId string) ([]models.GroupAlias, error) {
+	const op = "storage.repos.GetAliases"
+
+	id, err := strconv.Atoi(groupId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return nil, err
+	}
+
+	var group models.Group
+	if result := r.database.WithContext(ctx).Where("id = ?", id).First(&group); result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return nil, result.Error
+	}
+
+	var aliases []models.GroupAlias
+	result := r.database.WithContext(ctx).Where("group_id = ?", id).Order("id asc").Find(&aliases)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return nil, result.Error
+	}
+
+	return aliases, nil
+}
+
+func (r *groupRepo) AddAlias(ctx context.Context, newAlias models.GroupAlias) (uint, error) {
+	const op = "storage.repos.AddAlias"
+
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if result := tx.Where("id = ?", newAlias.GroupId).First(&group); result.Error != nil {
+			return result.Error
+		}
+
+		_, err := findGroup(tx, newAlias.Alias)
+		if err == nil {
+			return ErrAliasTaken
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// The unique index on alias_key catches a concurrent add of the
+		// same alias that got past the check above.
+		newAlias.Id = 0
+		if result := tx.Create(&newAlias); result.Error != nil {
+			if duplicatedKey(tx, result.Error) {
+				return ErrAliasTaken
+			}
+			return result.Error
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err != ErrAliasTaken {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		}
+		return 0, err
+	}
+
+	return newAlias.Id, nil
+}
+
+func (r *groupRepo) DeleteAlias(ctx context.Context, id string) error {
+	const op = "storage.repos.DeleteAlias"
+
+	aliasId, err := strconv.Atoi(id)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	result := r.database.WithContext(ctx).Delete(&models.GroupAlias{}, aliasId)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetLyricsStats computes lyrics statistics over all songs of a group
+// and ranks the songs by how much of their lyrics repeats.
+func (r *groupRepo) GetLyricsStats(ctx context.Context, groupId string, top int) (models.GroupLyricsStats, error) {
+	const op = "storage.repos.GetLyricsStats"
+
+	id, err := strconv.Atoi(groupId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return models.GroupLyricsStats{}, err
+	}
+
+	var group models.Group
+	if result := r.database.WithContext(ctx).Where("id = ?", id).First(&group); result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return models.GroupLyricsStats{}, result.Error
+	}
+
+	stats := models.GroupLyricsStats{GroupId: group.Id, Band: group.Name, Ranking: []models.SongRepetition{}}
+	collector := lyricstats.NewCollector(top)
+
+	var songs []models.Song
+	result := r.database.WithContext(ctx).Select("id", "song", "text", "language").Where("group_id = ?", group.Id).
+		FindInBatches(&songs, 100, func(tx *gorm.DB, batch int) error {
+			for _, song := range songs {
+				collector.Add(song.Text, song.Language)
+				stats.Ranking = append(stats.Ranking, models.SongRepetition{
+					SongId:          song.Id,
+					Song:            song.Song,
+					RepetitionRatio: lyricstats.Compute(song.Text, song.Language, 0).RepetitionRatio,
+				})
+			}
+			return nil
+		})
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return models.GroupLyricsStats{}, result.Error
+	}
+
+	sort.SliceStable(stats.Ranking, func(i, j int) bool {
+		return stats.Ranking[i].RepetitionRatio > stats.Ranking[j].RepetitionRatio
+	})
+
+	stats.Songs = len(stats.Ranking)
+	stats.Stats = collector.Stats()
+
+	return stats, nil
+}
+
+// findGroup resolves a band name to its canonical group, first by the
+// group's own name and then by any of its aliases.
+func findGroup(db *gorm.DB, band string) (models.Group, error) {
+	key := normalize.NameKey(band)
+
+	var group models.Group
+	result := db.Where("name_key = ?", key).First(&group)
+	if result.Error == nil || !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return group, result.Error
+	}
+
+	result = db.Where("id IN (?)",
+		db.Model(&models.GroupAlias{}).Select("group_id").Where("alias_key = ?", key)).
+		First(&group)
+
+	return group, result.Error
+}
+
+// duplicatedKey reports whether err is a unique index violation, as the
+// dialect translates it.
+func duplicatedKey(db *gorm.DB, err error) bool {
+	translator, ok := db.Dialector.(gorm.ErrorTranslator)
+	return ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+}
+
+// lockGroup locks the row of a group until the transaction ends. Songs
+// are added to a group while holding it, so two concurrent adds can't
+// both miss the title the other one is adding.
+func lockGroup(tx *gorm.DB, groupId uint) error {
+	var group models.Group
+	result := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("id = ?", groupId).Take(&group)
+
+	return result.Error
+}
+
+// DeleteGroup moves a group and all of its songs to the trash. They share
+// the deletion time, which is how restoring the group finds its songs.
+func (r *groupRepo) DeleteGroup(ctx context.Context, id string) error {
+	const op = "storage.repos.DeleteGroup"
+
+	groupId, err := strconv.Atoi(id)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		if result := tx.Where("id = ?", groupId).First(&group); result.Error != nil {
+			return result.Error
+		}
+
+		var songs []models.Song
+		if result := tx.Where("group_id = ?", group.Id).Find(&songs); result.Error != nil {
+			return result.Error
+		}
+
+		now := time.Now()
+		if result := tx.Model(&models.Song{}).Where("group_id = ?", group.Id).Update("deleted_at", now); result.Error != nil {
+			return result.Error
+		}
+
+		before := group
+		if result := tx.Model(&group).Update("deleted_at", now); result.Error != nil {
+			return result.Error
+		}
+
+		for _, song := range songs {
+			if err := writeAudit(ctx, tx, models.AuditDelete, models.AuditSong, song.Id, song, nil); err != nil {
+				return err
+			}
+		}
+
+		return writeAudit(ctx, tx, models.AuditDelete, models.AuditGroup, group.Id, before, nil)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,344 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/normalize"
+	"test-case/internal/utils/songimport"
+	"test-case/internal/utils/textnorm"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultImportBatch = 500
+
+// maxImportErrors caps the row errors kept in a report, Failed still
+// counts all of them.
+const maxImportErrors = 1000
+
+type ImportOptions struct {
+	DryRun bool
+	// Resume skips the rows up to and including this row number.
+	Resume    int
+	BatchSize int
+}
+
+// importGroup caches a group and the title keys of its songs for the
+// duration of a batch, so that each group is looked up once per batch.
+// A dry run writes nothing and keeps them for the whole import instead.
+type importGroup struct {
+	group  models.Group
+	titles map[string]struct{}
+}
+
+type importer struct {
+	ctx     context.Context
+	db      *gorm.DB
+	options ImportOptions
+	report  models.ImportReport
+	groups  map[string]*importGroup
+	groupId map[uint]*importGroup
+}
+
+// ImportSongs reads songs from reader and stores them in batches, each
+// batch in its own transaction. Invalid and duplicate rows are reported
+// and skipped. The report is returned along with an error that stopped
+// the import, its LastRow telling where to resume.
+func (r *songRepo) ImportSongs(ctx context.Context, reader songimport.Reader, options ImportOptions) (models.ImportReport, error) {
+	const op = "storage.repos.ImportSongs"
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultImportBatch
+	}
+
+	imp := &importer{
+		ctx:     ctx,
+		db:      r.database.WithContext(ctx),
+		options: options,
+		report:  models.ImportReport{DryRun: options.DryRun, Errors: []models.ImportRowError{}},
+		groups:  make(map[string]*importGroup),
+		groupId: make(map[uint]*importGroup),
+	}
+
+	batch := make([]songimport.Row, 0, options.BatchSize)
+	last := options.Resume
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		var rowErr *songimport.RowError
+		if errors.As(err, &rowErr) {
+			imp.report.Read++
+			if rowErr.Number <= options.Resume {
+				imp.report.Skipped++
+				continue
+			}
+			imp.fail(songimport.Row{Number: rowErr.Number}, rowErr.Err)
+			last = rowErr.Number
+			continue
+		}
+		if err != nil {
+			// Keep the rows read before the file broke off, so that
+			// resuming starts right at the broken part.
+			if flushErr := imp.flush(batch, last); flushErr != nil {
+				err = flushErr
+			}
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+			return imp.finish(), err
+		}
+
+		imp.report.Read++
+		if row.Number <= options.Resume {
+			imp.report.Skipped++
+			continue
+		}
+
+		last = row.Number
+		if err := validateImportRow(row); err != nil {
+			imp.fail(row, err)
+			continue
+		}
+
+		batch = append(batch, row)
+		if len(batch) < options.BatchSize {
+			continue
+		}
+
+		if err := imp.flush(batch, last); err != nil {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+			return imp.finish(), err
+		}
+		batch = batch[:0]
+	}
+
+	if err := imp.flush(batch, last); err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return imp.finish(), err
+	}
+
+	return imp.finish(), nil
+}
+
+func validateImportRow(row songimport.Row) error {
+	if strings.TrimSpace(row.Band) == "" {
+		return errors.New("band is required")
+	}
+
+	if strings.TrimSpace(row.Song) == "" {
+		return errors.New("song is required")
+	}
+
+	if row.ReleaseDate != "" {
+		if _, err := time.Parse("02.01.2006", row.ReleaseDate); err != nil {
+			return errors.New("release date must be in DD.MM.YYYY format")
+		}
+	}
+
+	if row.Link != "" {
+		link, err := url.ParseRequestURI(row.Link)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") {
+			return errors.New("link must be an http or https URL")
+		}
+	}
+
+	return nil
+}
+
+func (imp *importer) fail(row songimport.Row, err error) {
+	imp.report.Failed++
+	if len(imp.report.Errors) >= maxImportErrors {
+		return
+	}
+	imp.report.Errors = append(imp.report.Errors, models.ImportRowError{
+		Row:   row.Number,
+		Band:  row.Band,
+		Song:  row.Song,
+		Error: err.Error(),
+	})
+}
+
+// finish orders the row errors, as duplicates are only found when their
+// batch is stored.
+func (imp *importer) finish() models.ImportReport {
+	sort.SliceStable(imp.report.Errors, func(i, j int) bool {
+		return imp.report.Errors[i].Row < imp.report.Errors[j].Row
+	})
+
+	return imp.report
+}
+
+// flush stores a batch of valid rows. last is the number of the last row
+// read, which may be an invalid one following the batch.
+func (imp *importer) flush(batch []songimport.Row, last int) error {
+	const op = "storage.repos.ImportSongs"
+
+	if len(batch) == 0 {
+		imp.report.LastRow = max(imp.report.LastRow, last)
+		return nil
+	}
+
+	// Titles read in an earlier batch may have been added to since, so
+	// every batch locks its groups and reads them again.
+	if !imp.options.DryRun {
+		imp.groups = make(map[string]*importGroup)
+		imp.groupId = make(map[uint]*importGroup)
+	}
+
+	var songs []models.Song
+	var bands []string
+	var duplicates []songimport.Row
+	err := imp.db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range batch {
+			group, err := imp.group(tx, row.Band)
+			if err != nil {
+				return err
+			}
+
+			key := normalize.TitleKey(row.Song)
+			if _, ok := group.titles[key]; ok {
+				duplicates = append(duplicates, row)
+				continue
+			}
+			group.titles[key] = struct{}{}
+
+			song := models.Song{
+				Song:        row.Song,
+				ReleaseDate: row.ReleaseDate,
+				Text:        textnorm.Default.Apply(row.Text),
+				Link:        row.Link,
+				GroupId:     group.group.Id,
+			}
+			analyzeSong(&song)
+			songs = append(songs, song)
+			bands = append(bands, group.group.Name)
+		}
+
+		if imp.options.DryRun || len(songs) == 0 {
+			return nil
+		}
+
+		// The songs go first to get their ids from the sequence.
+		if result := tx.CreateInBatches(&songs, 100); result.Error != nil {
+			return result.Error
+		}
+
+		// Everything a song carries is built up front and inserted in
+		// batches as well, instead of a handful of statements per row.
+		originals := make([]models.Lyrics, 0, len(songs))
+		revisions := make([]models.SongRevision, 0, len(songs))
+		entries := make([]models.AuditEntry, 0, len(songs))
+		var sections []models.LyricsSection
+		for i, song := range songs {
+			originals = append(originals, newOriginal(song))
+			sections = append(sections, newSections(song.Id, song.Text)...)
+			revisions = append(revisions, newRevision(imp.ctx, song, bands[i], 1, "Imported"))
+
+			entry, err := newAuditEntry(imp.ctx, models.AuditCreate, models.AuditSong, song.Id, nil, song)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+
+		for _, rows := range []interface{}{&originals, &sections, &revisions, &entries} {
+			if result := tx.CreateInBatches(rows, 100); result.Error != nil && !errors.Is(result.Error, gorm.ErrEmptySlice) {
+				return result.Error
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		// Groups created in the rolled back transaction are gone again.
+		imp.groups = make(map[string]*importGroup)
+		imp.groupId = make(map[uint]*importGroup)
+		return err
+	}
+
+	// Duplicates count only once their batch is in, as the rows of a
+	// rolled back batch are read again when the import is resumed.
+	for _, row := range duplicates {
+		imp.fail(row, ErrDuplicateSong)
+	}
+
+	if imp.options.DryRun {
+		imp.report.WouldImport += len(songs)
+	} else {
+		imp.report.Imported += len(songs)
+	}
+	imp.report.LastRow = last
+	logger.Ctx(imp.ctx).Info().Int("last row", last).Int("imported", imp.report.Imported).
+		Int("would import", imp.report.WouldImport).Msg(op)
+
+	return nil
+}
+
+// group finds the group of a band, creating it unless this is a dry run.
+func (imp *importer) group(tx *gorm.DB, band string) (*importGroup, error) {
+	key := normalize.NameKey(band)
+	if cached, ok := imp.groups[key]; ok {
+		return cached, nil
+	}
+
+	group, err := findGroup(tx, band)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err == nil {
+		if cached, ok := imp.groupId[group.Id]; ok {
+			imp.groups[key] = cached
+			return cached, nil
+		}
+
+		// Held until the batch is stored, as AddSong does for one song.
+		if !imp.options.DryRun {
+			if err := lockGroup(tx, group.Id); err != nil {
+				return nil, err
+			}
+		}
+
+		var titles []string
+		result := tx.Model(&models.Song{}).Where("group_id = ?", group.Id).Pluck("song", &titles)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		cached := &importGroup{group: group, titles: make(map[string]struct{}, len(titles))}
+		for _, title := range titles {
+			cached.titles[normalize.TitleKey(title)] = struct{}{}
+		}
+
+		imp.groups[key] = cached
+		imp.groupId[group.Id] = cached
+		return cached, nil
+	}
+
+	group = models.Group{Name: band}
+	if !imp.options.DryRun {
+		if result := tx.Create(&group); result.Error != nil {
+			return nil, result.Error
+		}
+
+		if err := writeAudit(imp.ctx, tx, models.AuditCreate, models.AuditGroup, group.Id, nil, group); err != nil {
+			return nil, err
+		}
+	}
+
+	cached := &importGroup{group: group, titles: make(map[string]struct{})}
+	imp.groups[key] = cached
+	if group.Id != 0 {
+		imp.groupId[group.Id] = cached
+	}
+
+	return cached, nil
+}
@@ -0,0 +1,385 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"test-case/internal/models"
+	"test-case/internal/utils/langdetect"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/lrc"
+	"test-case/internal/utils/lyrics"
+	"test-case/internal/utils/lyricstats"
+	"test-case/internal/utils/textnorm"
+
+	"gorm.io/gorm"
+)
+
+type LyricsRepository interface {
+	GetSections(ctx context.Context, songId string) ([]lyrics.Section, error)
+	GetSynced(ctx context.Context, songId string) (lrc.Lyrics, error)
+	SetSynced(ctx context.Context, songId string, synced lrc.Lyrics) error
+	GetTranslations(ctx context.Context, songId string) ([]models.Lyrics, error)
+	SetTranslation(ctx context.Context, translation models.Lyrics) error
+	DeleteTranslation(ctx context.Context, songId string, language string) error
+	GetStats(ctx context.Context, songId string, top int) (lyricstats.Stats, error)
+}
+
+var (
+	ErrNoSyncedLyrics = errors.New("song has no synced lyrics")
+	ErrLanguageTaken  = errors.New("language is already used by the original lyrics")
+)
+
+type lyricsRepo struct {
+	database *gorm.DB
+}
+
+func NewLyricsRepository(db *gorm.DB) LyricsRepository {
+	return &lyricsRepo{database: db}
+}
+
+func (r *lyricsRepo) GetSections(ctx context.Context, songId string) ([]lyrics.Section, error) {
+	const op = "storage.repos.GetSections"
+
+	id, err := strconv.Atoi(songId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return nil, err
+	}
+
+	var song models.Song
+	result := r.database.WithContext(ctx).Preload("Sections", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position asc")
+	}).Where("id = ?", id).First(&song)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return nil, result.Error
+	}
+
+	// Songs stored before sections existed are parsed on the fly.
+	if len(song.Sections) == 0 {
+		return lyrics.Parse(song.Text), nil
+	}
+
+	sections := make([]lyrics.Section, 0, len(song.Sections))
+	for _, section := range song.Sections {
+		sections = append(sections, lyrics.Section{
+			Kind:     section.Kind,
+			Label:    section.Label,
+			Text:     section.Text,
+			RepeatOf: section.RepeatOf,
+		})
+	}
+
+	return sections, nil
+}
+
+func (r *lyricsRepo) GetSynced(ctx context.Context, songId string) (lrc.Lyrics, error) {
+	const op = "storage.repos.GetSynced"
+
+	id, err := strconv.Atoi(songId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return lrc.Lyrics{}, err
+	}
+
+	var song models.Song
+	result := r.database.WithContext(ctx).Preload("Group").Preload("SyncedLines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position asc")
+	}).Where("id = ?", id).First(&song)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return lrc.Lyrics{}, result.Error
+	}
+
+	if len(song.SyncedLines) == 0 {
+		return lrc.Lyrics{}, ErrNoSyncedLyrics
+	}
+
+	synced := lrc.Lyrics{
+		Tags:  map[string]string{"ar": song.Group.Name, "ti": song.Song},
+		Lines: make([]lrc.Line, 0, len(song.SyncedLines)),
+	}
+	for _, line := range song.SyncedLines {
+		words := make([]lrc.Word, 0, len(line.Words))
+		for _, word := range line.Words {
+			words = append(words, lrc.Word{Time: word.Time, Text: word.Text})
+		}
+		synced.Lines = append(synced.Lines, lrc.Line{Time: line.Time, Text: line.Text, Words: words})
+	}
+
+	return synced, nil
+}
+
+// SetSynced replaces the timed lines of a song. The plain text and its
+// sections are derived from the synced version.
+func (r *lyricsRepo) SetSynced(ctx context.Context, songId string, synced lrc.Lyrics) error {
+	const op = "storage.repos.SetSynced"
+
+	id, err := strconv.Atoi(songId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song models.Song
+		if result := tx.Where("id = ?", id).First(&song); result.Error != nil {
+			return result.Error
+		}
+		before := song
+
+		if err := tx.Where("song_id = ?", song.Id).Delete(&models.SyncedLine{}).Error; err != nil {
+			return err
+		}
+
+		lines := make([]models.SyncedLine, 0, len(synced.Lines))
+		for position, line := range synced.Lines {
+			words := make([]models.SyncedWord, 0, len(line.Words))
+			for _, word := range line.Words {
+				words = append(words, models.SyncedWord{Time: word.Time, Text: word.Text})
+			}
+			lines = append(lines, models.SyncedLine{
+				SongId:   song.Id,
+				Position: position,
+				Time:     line.Time,
+				Text:     textnorm.Default.Apply(line.Text),
+				Words:    words,
+			})
+		}
+		if len(lines) > 0 {
+			if err := tx.Create(&lines).Error; err != nil {
+				return err
+			}
+		}
+
+		// Normalized like any other text, so a later update that only
+		// normalizes it again keeps the timings.
+		// The lines were just replaced, so saveText rather than saveSong.
+		song.Text = textnorm.Default.Apply(synced.Text())
+		if err := saveText(tx, &song, true); err != nil {
+			return err
+		}
+
+		if _, err := recordRevision(ctx, tx, song, "Synced lyrics imported"); err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditUpdate, models.AuditSong, song.Id, before, song)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	return nil
+}
+
+// GetTranslations returns every lyrics entry of a song, the original
+// first and the rest ordered by language.
+func (r *lyricsRepo) GetTranslations(ctx context.Context, songId string) ([]models.Lyrics, error) {
+	const op = "storage.repos.GetTranslations"
+
+	id, err := strconv.Atoi(songId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return nil, err
+	}
+
+	var song models.Song
+	result := r.database.WithContext(ctx).Preload("Lyrics", func(db *gorm.DB) *gorm.DB {
+		return db.Order("kind = 'original' desc, language asc")
+	}).Where("id = ?", id).First(&song)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return nil, result.Error
+	}
+
+	if len(song.Lyrics) == 0 || song.Lyrics[0].Kind != models.LyricsOriginal {
+		original := models.Lyrics{SongId: song.Id, Language: "und", Kind: models.LyricsOriginal, Text: song.Text}
+		song.Lyrics = append([]models.Lyrics{original}, song.Lyrics...)
+	}
+
+	return song.Lyrics, nil
+}
+
+// SetTranslation creates or replaces the translation or transliteration
+// of a song in one language.
+func (r *lyricsRepo) SetTranslation(ctx context.Context, translation models.Lyrics) error {
+	const op = "storage.repos.SetTranslation"
+
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song models.Song
+		if result := tx.Where("id = ?", translation.SongId).First(&song); result.Error != nil {
+			return result.Error
+		}
+
+		var existing models.Lyrics
+		result := tx.Where("song_id = ? AND language = ?", song.Id, translation.Language).First(&existing)
+		if result.Error != nil {
+			if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return result.Error
+			}
+			translation.Id = 0
+			if err := tx.Create(&translation).Error; err != nil {
+				return err
+			}
+
+			return writeAudit(ctx, tx, models.AuditCreate, models.AuditLyrics, translation.Id, nil, translation)
+		}
+
+		if existing.Kind == models.LyricsOriginal {
+			return ErrLanguageTaken
+		}
+
+		before := existing
+		existing.Kind = translation.Kind
+		existing.Text = translation.Text
+		if err := tx.Save(&existing).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditUpdate, models.AuditLyrics, existing.Id, before, existing)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	return nil
+}
+
+func (r *lyricsRepo) DeleteTranslation(ctx context.Context, songId string, language string) error {
+	const op = "storage.repos.DeleteTranslation"
+
+	id, err := strconv.Atoi(songId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.Lyrics
+		result := tx.Where("song_id = ? AND language = ? AND kind <> ?", id, language, models.LyricsOriginal).
+			First(&existing)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Delete(&existing).Error; err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditDelete, models.AuditLyrics, existing.Id, existing, nil)
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (r *lyricsRepo) GetStats(ctx context.Context, songId string, top int) (lyricstats.Stats, error) {
+	const op = "storage.repos.GetStats"
+
+	id, err := strconv.Atoi(songId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return lyricstats.Stats{}, err
+	}
+
+	var song models.Song
+	result := r.database.WithContext(ctx).Select("id", "text", "language").Where("id = ?", id).First(&song)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return lyricstats.Stats{}, result.Error
+	}
+
+	return lyricstats.Compute(song.Text, song.Language, top), nil
+}
+
+// saveOriginal keeps the original lyrics entry in sync with the text
+// and detected language of a song. The language stays undetermined when
+// a translation already uses it.
+func saveOriginal(tx *gorm.DB, song models.Song) error {
+	language := song.Language
+	if language == "" {
+		language = langdetect.Undetermined
+	}
+
+	var taken int64
+	result := tx.Model(&models.Lyrics{}).
+		Where("song_id = ? AND language = ? AND kind <> ?", song.Id, language, models.LyricsOriginal).
+		Count(&taken)
+	if result.Error != nil {
+		return result.Error
+	}
+	if taken > 0 {
+		language = langdetect.Undetermined
+	}
+
+	result = tx.Model(&models.Lyrics{}).
+		Where("song_id = ? AND kind = ?", song.Id, models.LyricsOriginal).
+		Updates(map[string]interface{}{"text": song.Text, "language": language})
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+
+	original := newOriginal(song)
+	original.Language = language
+
+	return tx.Create(&original).Error
+}
+
+// newOriginal builds the original lyrics entry of a song that has no
+// translations yet.
+func newOriginal(song models.Song) models.Lyrics {
+	language := song.Language
+	if language == "" {
+		language = langdetect.Undetermined
+	}
+
+	return models.Lyrics{
+		SongId:   song.Id,
+		Language: language,
+		Kind:     models.LyricsOriginal,
+		Text:     song.Text,
+	}
+}
+
+// saveSections replaces the stored sections of a song with the ones
+// parsed from its plain text.
+func saveSections(tx *gorm.DB, songId uint, text string) error {
+	if err := tx.Where("song_id = ?", songId).Delete(&models.LyricsSection{}).Error; err != nil {
+		return err
+	}
+
+	sections := newSections(songId, text)
+	if len(sections) == 0 {
+		return nil
+	}
+
+	return tx.Create(&sections).Error
+}
+
+// newSections parses plain text into the section rows of a song.
+func newSections(songId uint, text string) []models.LyricsSection {
+	parsed := lyrics.Parse(text)
+
+	sections := make([]models.LyricsSection, 0, len(parsed))
+	for position, section := range parsed {
+		sections = append(sections, models.LyricsSection{
+			SongId:   songId,
+			Position: position,
+			Kind:     section.Kind,
+			Label:    section.Label,
+			Text:     section.Text,
+			RepeatOf: section.RepeatOf,
+		})
+	}
+
+	return sections
+}
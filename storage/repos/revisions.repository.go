@@ -0,0 +1,211 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"test-case/internal/models"
+	"test-case/internal/utils/diff"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/requestinfo"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevisionRepository interface {
+	GetRevisions(ctx context.Context, songId string) ([]models.SongRevision, error)
+	DiffRevisions(ctx context.Context, songId string, from string, to string) (models.RevisionDiff, error)
+	RollbackSong(ctx context.Context, songId string, number string) (int, error)
+}
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+type revisionRepo struct {
+	database *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) RevisionRepository {
+	return &revisionRepo{database: db}
+}
+
+func (r *revisionRepo) GetRevisions(ctx context.Context, songId string) ([]models.SongRevision, error) {
+	const op = "storage.repos.GetRevisions"
+
+	id, err := strconv.Atoi(songId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return nil, err
+	}
+
+	var song models.Song
+	result := r.database.WithContext(ctx).Preload("Revisions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number desc")
+	}).Where("id = ?", id).First(&song)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return nil, result.Error
+	}
+
+	return song.Revisions, nil
+}
+
+func (r *revisionRepo) DiffRevisions(ctx context.Context, songId string, from string, to string) (models.RevisionDiff, error) {
+	const op = "storage.repos.DiffRevisions"
+
+	older, err := r.getRevision(ctx, songId, from)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return models.RevisionDiff{}, err
+	}
+
+	newer, err := r.getRevision(ctx, songId, to)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return models.RevisionDiff{}, err
+	}
+
+	result := models.RevisionDiff{
+		From:   older.Number,
+		To:     newer.Number,
+		Fields: make(map[string][2]string),
+		Text:   diff.Lines(older.Text, newer.Text),
+	}
+
+	fields := map[string][2]string{
+		"Band":        {older.Band, newer.Band},
+		"Song":        {older.Song, newer.Song},
+		"ReleaseDate": {older.ReleaseDate, newer.ReleaseDate},
+		"Link":        {older.Link, newer.Link},
+	}
+	for name, values := range fields {
+		if values[0] != values[1] {
+			result.Fields[name] = values
+		}
+	}
+
+	return result, nil
+}
+
+// RollbackSong restores the lyrics and metadata of a song from an older
+// revision. The rollback itself is recorded as a new revision, whose
+// number is returned.
+func (r *revisionRepo) RollbackSong(ctx context.Context, songId string, number string) (int, error) {
+	const op = "storage.repos.RollbackSong"
+
+	revision, err := r.getRevision(ctx, songId, number)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return 0, err
+	}
+
+	var recorded int
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song models.Song
+		if result := tx.Where("id = ?", revision.SongId).First(&song); result.Error != nil {
+			return result.Error
+		}
+
+		before := song
+		textChanged := song.Text != revision.Text
+
+		song.Song = revision.Song
+		song.ReleaseDate = revision.ReleaseDate
+		song.Text = revision.Text
+		song.Link = revision.Link
+
+		if err := saveSong(tx, &song, textChanged); err != nil {
+			return err
+		}
+
+		recorded, err = recordRevision(ctx, tx, song, fmt.Sprintf("Rollback to revision %d", revision.Number))
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditUpdate, models.AuditSong, song.Id, before, song)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return 0, err
+	}
+
+	return recorded, nil
+}
+
+func (r *revisionRepo) getRevision(ctx context.Context, songId string, number string) (models.SongRevision, error) {
+	id, err := strconv.Atoi(songId)
+	if err != nil {
+		return models.SongRevision{}, err
+	}
+
+	revisionNumber, err := strconv.Atoi(number)
+	if err != nil {
+		return models.SongRevision{}, err
+	}
+
+	var revision models.SongRevision
+	result := r.database.WithContext(ctx).Where("song_id = ? AND number = ?", id, revisionNumber).First(&revision)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.SongRevision{}, ErrRevisionNotFound
+	}
+
+	return revision, result.Error
+}
+
+// recordRevision stores a snapshot of a song after a change, taking the
+// author and the reason from the request context. The action describes
+// changes made by the service itself and prefixes the given reason.
+func recordRevision(ctx context.Context, tx *gorm.DB, song models.Song, action string) (int, error) {
+	var band string
+	result := tx.Model(&models.Group{}).Select("name").Where("id = ?", song.GroupId).Scan(&band)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	// The lock on the song row makes concurrent changes of the song take
+	// their numbers one after the other instead of both taking the same.
+	var locked models.Song
+	result = tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Where("id = ?", song.Id).Take(&locked)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	var last int
+	result = tx.Model(&models.SongRevision{}).Select("COALESCE(MAX(number), 0)").
+		Where("song_id = ?", song.Id).Scan(&last)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	revision := newRevision(ctx, song, band, last+1, action)
+
+	return revision.Number, tx.Create(&revision).Error
+}
+
+// newRevision builds a numbered snapshot of a song, with the author and
+// the reason taken from the request context.
+func newRevision(ctx context.Context, song models.Song, band string, number int, action string) models.SongRevision {
+	info := requestinfo.FromContext(ctx)
+
+	reason := info.Reason
+	if action != "" && reason != "" {
+		reason = action + ": " + reason
+	} else if action != "" {
+		reason = action
+	}
+
+	return models.SongRevision{
+		SongId:      song.Id,
+		Number:      number,
+		Author:      info.Author,
+		Reason:      reason,
+		Band:        band,
+		Song:        song.Song,
+		ReleaseDate: song.ReleaseDate,
+		Text:        song.Text,
+		Link:        song.Link,
+	}
+}
@@ -0,0 +1,75 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScanRepository interface {
+	IsKnown(ctx context.Context, path string, hash string) (bool, error)
+	RecordFile(ctx context.Context, file models.ScannedFile) error
+}
+
+type scanRepo struct {
+	database *gorm.DB
+}
+
+func NewScanRepository(db *gorm.DB) ScanRepository {
+	return &scanRepo{database: db}
+}
+
+// IsKnown tells whether the file was scanned before with the same
+// content. A copy or a moved file with known content counts as known.
+func (r *scanRepo) IsKnown(ctx context.Context, path string, hash string) (bool, error) {
+	const op = "storage.repos.IsKnown"
+
+	var file models.ScannedFile
+	result := r.database.WithContext(ctx).Where("path = ? AND hash = ?", path, hash).First(&file)
+	if result.Error == nil {
+		return true, nil
+	}
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return false, result.Error
+	}
+
+	result = r.database.WithContext(ctx).Where("hash = ?", hash).First(&file)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return false, result.Error
+	}
+
+	// Remember the new path as well, pointing at the same song.
+	file.Id = 0
+	file.Path = path
+	if err := r.RecordFile(ctx, file); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RecordFile stores the outcome of scanning a file, replacing an earlier
+// record of the same path.
+func (r *scanRepo) RecordFile(ctx context.Context, file models.ScannedFile) error {
+	const op = "storage.repos.RecordFile"
+
+	result := r.database.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		UpdateAll: true,
+	}).Create(&file)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return result.Error
+	}
+
+	return nil
+}
@@ -1,23 +1,42 @@
 package repos
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"test-case/internal/models"
 	"test-case/internal/utils/logger"
+	"test-case/internal/utils/normalize"
 	"test-case/internal/utils/paginates"
+	"test-case/internal/utils/similarity"
+	"test-case/internal/utils/songimport"
+	"test-case/internal/utils/textnorm"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SongRepository interface {
-	GetSongs(filterParams map[string]string, page string, limit string) ([]models.Song, error)
-	GetSongText(id string) (string, error)
-	DeleteSong(id string) error
-	UpdateSong(updatedSong models.Song) error
-	AddSong(newSong models.Song) (uint, error)
+	GetSongs(ctx context.Context, filterParams map[string]string, page string, limit string) ([]models.Song, error)
+	GetSongText(ctx context.Context, id string) (string, error)
+	DeleteSong(ctx context.Context, id string) error
+	UpdateSong(ctx context.Context, updatedSong models.Song) error
+	AddSong(ctx context.Context, newSong models.Song) (uint, error)
+	FindDuplicates(ctx context.Context, threshold float64) ([]models.DuplicateCandidate, error)
+	MergeSongs(ctx context.Context, keepId string, mergeId string) error
+	DetectLanguages(ctx context.Context, all bool) (int, error)
+	SetExplicitOverride(ctx context.Context, id string, override *bool) error
+	FlagExplicit(ctx context.Context) (int, error)
+	NormalizeTexts(ctx context.Context, dryRun bool) (int, error)
+	ImportSongs(ctx context.Context, reader songimport.Reader, options ImportOptions) (models.ImportReport, error)
+	ExportSongs(ctx context.Context, filterParams map[string]string, export func(models.Song) error) error
 }
 
+var ErrDuplicateSong = errors.New("song already exists")
+
 type songRepo struct {
 	database *gorm.DB
 }
@@ -26,13 +45,41 @@ func NewSongRepository(db *gorm.DB) SongRepository {
 	return &songRepo{database: db}
 }
 
-func (r *songRepo) GetSongs(filterParams map[string]string, page string, limit string) ([]models.Song, error) {
+func (r *songRepo) GetSongs(ctx context.Context, filterParams map[string]string, page string, limit string) ([]models.Song, error) {
 	const op = "storage.repos.GetSongs"
 
-	query := r.database
+	query, err := r.filterSongs(filterParams)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return nil, err
+	}
+
+	var songs []models.Song
+	result := query.WithContext(ctx).Order("id asc").Scopes(paginates.SongPaginate(page, limit)).Find(&songs)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return nil, result.Error
+	}
+
+	return songs, nil
+}
+
+// filterSongs builds the song query shared by the list and the export
+// from the query parameters of a request.
+func (r *songRepo) filterSongs(filterParams map[string]string) (*gorm.DB, error) {
+	query := r.database.Model(&models.Song{})
 
 	if filterParams["band"] != "" {
-		query = query.Where("band = ?", filterParams["band"])
+		key := normalize.NameKey(filterParams["band"])
+		query = query.Where("group_id IN (?) OR group_id IN (?)",
+			r.database.Model(&models.Group{}).Select("id").Where("name_key = ?", key),
+			r.database.Model(&models.GroupAlias{}).Select("group_id").Where("alias_key = ?", key))
+	}
+	if filterParams["bandSearch"] != "" {
+		pattern := "%" + escapeLike(normalize.NameKey(filterParams["bandSearch"])) + "%"
+		query = query.Where("group_id IN (?) OR group_id IN (?)",
+			r.database.Model(&models.Group{}).Select("id").Where(`name_key LIKE ? ESCAPE '\'`, pattern),
+			r.database.Model(&models.GroupAlias{}).Select("group_id").Where(`alias_key LIKE ? ESCAPE '\'`, pattern))
 	}
 	if filterParams["song"] != "" {
 		query = query.Where("song = ?", filterParams["song"])
@@ -46,116 +93,690 @@ func (r *songRepo) GetSongs(filterParams map[string]string, page string, limit s
 	if filterParams["link"] != "" {
 		query = query.Where("link = ?", filterParams["link"])
 	}
+	if filterParams["language"] != "" {
+		query = query.Where("language = ?", filterParams["language"])
+	}
+	if filterParams["explicit"] != "" {
+		flag, err := strconv.ParseBool(filterParams["explicit"])
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("explicit = ?", flag)
+	}
 
-	var songs []models.Song
-	result := query.Order("id asc").Scopes(paginates.SongPaginate(page, limit)).Find(&songs)
-	if result.Error != nil {
-		logger.Logger.Info().Interface("Error occured: ", result.Error).Msg(op)
-		return nil, result.Error
+	return query, nil
+}
+
+// likeEscaper escapes the wildcards of LIKE, so a search for "100%"
+// matches the name literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// ExportSongs passes every song matching the filters to export, reading
+// them from a database cursor instead of loading them all at once.
+func (r *songRepo) ExportSongs(ctx context.Context, filterParams map[string]string, export func(models.Song) error) error {
+	const op = "storage.repos.ExportSongs"
+
+	query, err := r.filterSongs(filterParams)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
 	}
 
-	return songs, nil
+	// The band names come with the songs, so the export holds no more
+	// than one song in memory however many groups there are.
+	query = query.WithContext(ctx).Joins("Group").Order("songs.id asc")
+	rows, err := query.Rows()
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+	defer rows.Close()
+
+	exported := 0
+	for rows.Next() {
+		var song models.Song
+		if err := query.ScanRows(rows, &song); err != nil {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+			return err
+		}
+
+		song.Band = song.Group.Name
+		if err := export(song); err != nil {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+			return err
+		}
+		exported++
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	logger.Ctx(ctx).Info().Int("exported", exported).Msg(op)
+
+	return nil
 }
 
-func (r *songRepo) GetSongText(id string) (string, error) {
+func (r *songRepo) GetSongText(ctx context.Context, id string) (string, error) {
 	const op = "storage.repos.GetSongText"
 
 	songId, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Logger.Info().Interface("Error occured: ", err).Msg(op)
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
 		return "", err
 	}
 
 	var text string
-	result := r.database.Model(&models.Song{}).
+	result := r.database.WithContext(ctx).Model(&models.Song{}).
 		Select("text").Where("id = ?", songId).Scan(&text)
 	if result.Error != nil {
-		logger.Logger.Info().Interface("Error occured: ", result.Error).Msg(op)
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
 		return "", result.Error
 	}
 
 	return text, nil
 }
 
-func (r *songRepo) DeleteSong(id string) error {
+// DeleteSong moves a song to the trash. It stays restorable until it is
+// purged, see TrashRepository.
+func (r *songRepo) DeleteSong(ctx context.Context, id string) error {
 	const op = "storage.repos.DeleteSong"
 
 	songId, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Logger.Info().Interface("Error occured: ", err).Msg(op)
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
 		return err
 	}
 
-	result := r.database.Where(&models.Song{Id: uint(songId)}).Delete(&models.Song{})
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song models.Song
+		if result := tx.Where("id = ?", songId).First(&song); result.Error != nil {
+			return result.Error
+		}
+
+		before := song
+		if result := tx.Delete(&song); result.Error != nil {
+			return result.Error
+		}
+
+		return writeAudit(ctx, tx, models.AuditDelete, models.AuditSong, song.Id, before, nil)
+	})
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		}
+		return err
 	}
 
+	return nil
+}
+
+func (r *songRepo) UpdateSong(ctx context.Context, updatedSong models.Song) error {
+	const op = "storage.repos.UpdateSong"
+
+	updatedSong.Text = textnorm.Default.Apply(updatedSong.Text)
+
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The row stays locked until the update is stored, so the revision
+		// and the audit entry start from the state this update replaces.
+		var oldSong models.Song
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", updatedSong.Id).First(&oldSong)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Text stored before normalization, or with other steps, only
+		// counts as changed when it differs after normalizing it the same
+		// way, so a metadata update keeps the synced lines.
+		textChanged := textnorm.Default.Apply(oldSong.Text) != updatedSong.Text
+		if !textChanged {
+			updatedSong.Text = oldSong.Text
+		}
+		if !textChanged && oldSong.Song == updatedSong.Song &&
+			oldSong.ReleaseDate == updatedSong.ReleaseDate && oldSong.Link == updatedSong.Link {
+			return nil
+		}
+
+		before := oldSong
+
+		oldSong.Song = updatedSong.Song
+		oldSong.Group = updatedSong.Group
+		oldSong.Text = updatedSong.Text
+		oldSong.ReleaseDate = updatedSong.ReleaseDate
+		oldSong.Link = updatedSong.Link
+
+		if err := saveSong(tx, &oldSong, textChanged); err != nil {
+			return err
+		}
+
+		if _, err := recordRevision(ctx, tx, oldSong, ""); err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditUpdate, models.AuditSong, oldSong.Id, before, oldSong)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	return nil
+}
+
+func (r *songRepo) AddSong(ctx context.Context, newSong models.Song) (uint, error) {
+	const op = "storage.repos.AddSong"
+
+	newSong.Text = textnorm.Default.Apply(newSong.Text)
+
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		group, err := findGroup(tx, newSong.Band)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			logger.Ctx(ctx).Info().Str("Group not found", "Creating...").Msg(op)
+
+			group = models.Group{Name: newSong.Band}
+			if result := tx.Create(&group); result.Error != nil {
+				return result.Error
+			}
+
+			if err := writeAudit(ctx, tx, models.AuditCreate, models.AuditGroup, group.Id, nil, group); err != nil {
+				return err
+			}
+		}
+
+		if err := lockGroup(tx, group.Id); err != nil {
+			return err
+		}
+
+		var groupSongs []string
+		result := tx.Model(&models.Song{}).
+			Where("group_id = ?", group.Id).Pluck("song", &groupSongs)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		for _, title := range groupSongs {
+			if normalize.TitleKey(title) == normalize.TitleKey(newSong.Song) {
+				logger.Ctx(ctx).Info().Str("Duplicate song", newSong.Song).Msg(op)
+				return ErrDuplicateSong
+			}
+		}
+
+		// The id comes from the sequence, trashed songs keep theirs.
+		newSong.Id = 0
+		newSong.GroupId = group.Id
+		analyzeSong(&newSong)
+
+		if result := tx.Create(&newSong); result.Error != nil {
+			return result.Error
+		}
+
+		if err := saveOriginal(tx, newSong); err != nil {
+			return err
+		}
+
+		if err := saveSections(tx, newSong.Id, newSong.Text); err != nil {
+			return err
+		}
+
+		if _, err := recordRevision(ctx, tx, newSong, ""); err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditCreate, models.AuditSong, newSong.Id, nil, newSong)
+	})
+	if err != nil {
+		if err != ErrDuplicateSong {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		}
+		return 0, err
+	}
+
+	return newSong.Id, nil
+}
+
+// FindDuplicates compares the titles of the songs of each band first and
+// loads the lyrics only of the pairs whose titles are close enough to
+// reach the threshold.
+func (r *songRepo) FindDuplicates(ctx context.Context, threshold float64) ([]models.DuplicateCandidate, error) {
+	const op = "storage.repos.FindDuplicates"
+
+	var songs []models.Song
+	result := r.database.WithContext(ctx).Select("songs.id", "songs.song", "songs.group_id").
+		Joins("Group").Order("songs.id asc").Find(&songs)
 	if result.Error != nil {
-		logger.Logger.Info().Interface("Error occured: ", result.Error).Msg(op)
-		return result.Error
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return nil, result.Error
+	}
+
+	bands := make(map[string][]models.Song)
+	for _, song := range songs {
+		song.Band = song.Group.Name
+		key := normalize.Key(song.Band)
+		bands[key] = append(bands[key], song)
+	}
+
+	type pair struct {
+		a, b  models.Song
+		title float64
+	}
+
+	var pairs []pair
+	texts := make(map[uint]string)
+	for _, songs := range bands {
+		for i := 0; i < len(songs); i++ {
+			for j := i + 1; j < len(songs); j++ {
+				// Identical lyrics would raise the score at most this far.
+				title := similarity.Ratio(songs[i].Song, songs[j].Song)
+				if max(title, (1-duplicateLyricsWeight)*title+duplicateLyricsWeight) < threshold {
+					continue
+				}
+
+				pairs = append(pairs, pair{a: songs[i], b: songs[j], title: title})
+				texts[songs[i].Id] = ""
+				texts[songs[j].Id] = ""
+			}
+		}
+	}
+
+	if err := r.loadTexts(ctx, texts); err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return nil, err
+	}
+
+	candidates := make([]models.DuplicateCandidate, 0)
+	for _, pair := range pairs {
+		score := duplicateScore(pair.title, texts[pair.a.Id], texts[pair.b.Id])
+		if score < threshold {
+			continue
+		}
+
+		candidates = append(candidates, models.DuplicateCandidate{
+			SongId:      pair.a.Id,
+			DuplicateId: pair.b.Id,
+			Band:        pair.a.Band,
+			Song:        pair.a.Song,
+			Duplicate:   pair.b.Song,
+			Score:       score,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].SongId < candidates[j].SongId
+	})
+
+	return candidates, nil
+}
+
+// loadTexts fills in the lyrics of the songs whose ids are the keys of
+// texts, a batch of ids per query.
+func (r *songRepo) loadTexts(ctx context.Context, texts map[uint]string) error {
+	const batchSize = 1000
+
+	ids := make([]uint, 0, len(texts))
+	for id := range texts {
+		ids = append(ids, id)
+	}
+
+	for len(ids) > 0 {
+		batch := ids[:min(batchSize, len(ids))]
+		ids = ids[len(batch):]
+
+		var songs []models.Song
+		result := r.database.WithContext(ctx).Select("id", "text").Where("id IN ?", batch).Find(&songs)
+		if result.Error != nil {
+			return result.Error
+		}
+		for _, song := range songs {
+			texts[song.Id] = song.Text
+		}
 	}
 
 	return nil
 }
 
-func (r *songRepo) UpdateSong(updatedSong models.Song) error {
-	const op = "storage.repos.UpdateSong"
+func (r *songRepo) MergeSongs(ctx context.Context, keepId string, mergeId string) error {
+	const op = "storage.repos.MergeSongs"
+
+	keptId, err := strconv.Atoi(keepId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	mergedId, err := strconv.Atoi(mergeId)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	if keptId == mergedId {
+		return errors.New("cannot merge a song into itself")
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var kept, merged models.Song
+		if result := tx.Where("id = ?", keptId).First(&kept); result.Error != nil {
+			return result.Error
+		}
+		if result := tx.Where("id = ?", mergedId).First(&merged); result.Error != nil {
+			return result.Error
+		}
+
+		before := kept
+
+		if kept.ReleaseDate == "" {
+			kept.ReleaseDate = merged.ReleaseDate
+		}
+		textChanged := kept.Text == "" && merged.Text != ""
+		if textChanged {
+			kept.Text = merged.Text
+		}
+		if kept.Link == "" {
+			kept.Link = merged.Link
+		}
+
+		if err := saveSong(tx, &kept, textChanged); err != nil {
+			return err
+		}
+
+		// Translations the kept song lacks are taken over from the duplicate.
+		result := tx.Model(&models.Lyrics{}).
+			Where("song_id = ? AND kind <> ?", merged.Id, models.LyricsOriginal).
+			Where("language NOT IN (?)", tx.Model(&models.Lyrics{}).Select("language").Where("song_id = ?", kept.Id)).
+			Update("song_id", kept.Id)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result := tx.Delete(&models.Song{}, merged.Id); result.Error != nil {
+			return result.Error
+		}
+
+		err := tx.Create(&models.SongMerge{
+			KeptId:     kept.Id,
+			MergedId:   merged.Id,
+			MergedSong: merged.Song,
+			Score:      duplicateScore(similarity.Ratio(kept.Song, merged.Song), kept.Text, merged.Text),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if _, err := recordRevision(ctx, tx, kept, fmt.Sprintf("Merged song %d", merged.Id)); err != nil {
+			return err
+		}
+
+		if err := writeAudit(ctx, tx, models.AuditMerge, models.AuditSong, kept.Id, before, kept); err != nil {
+			return err
+		}
+
+		return writeAudit(ctx, tx, models.AuditDelete, models.AuditSong, merged.Id, merged, nil)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	logger.Ctx(ctx).Info().Int("kept", keptId).Int("merged", mergedId).Msg("Songs merged")
+
+	return nil
+}
+
+// duplicateLyricsWeight is the share of lyric similarity in the score of
+// a duplicate, the rest being title similarity.
+const duplicateLyricsWeight = 0.4
+
+// duplicateScore weighs title similarity against lyric similarity.
+// Songs without lyrics on either side are compared by title alone.
+func duplicateScore(title float64, a string, b string) float64 {
+	if a == "" || b == "" {
+		return title
+	}
+
+	return (1-duplicateLyricsWeight)*title + duplicateLyricsWeight*similarity.Lyrics(a, b)
+}
+
+// DetectLanguages runs language detection over stored songs in batches.
+// Unless all is set, only songs that were never analyzed are processed.
+func (r *songRepo) DetectLanguages(ctx context.Context, all bool) (int, error) {
+	const op = "storage.repos.DetectLanguages"
+
+	query := r.database.WithContext(ctx).Model(&models.Song{})
+	if !all {
+		query = query.Where("language = '' OR language IS NULL")
+	}
+
+	processed := 0
+	var songs []models.Song
+	result := query.FindInBatches(&songs, 100, func(tx *gorm.DB, batch int) error {
+		return r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, song := range songs {
+				before := song
+				detectLanguage(&song)
+
+				result := tx.Model(&song).Select("language", "language_confidence").Updates(&song)
+				if result.Error != nil {
+					return result.Error
+				}
+
+				if err := saveOriginal(tx, song); err != nil {
+					return err
+				}
 
-	var oldSong models.Song
-	result := r.database.Where("id = ?", updatedSong.Id).First(&oldSong)
+				if song.Language == before.Language {
+					continue
+				}
+				if err := writeAudit(ctx, tx, models.AuditUpdate, models.AuditSong, song.Id, before, song); err != nil {
+					return err
+				}
+			}
+
+			processed += len(songs)
+			logger.Ctx(ctx).Info().Int("processed", processed).Msg(op)
+
+			return nil
+		})
+	})
 	if result.Error != nil {
-		logger.Logger.Info().Interface("Error occured: ", result.Error).Msg(op)
-		return result.Error
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return processed, result.Error
+	}
+
+	return processed, nil
+}
+
+// saveSong writes a changed song. When its text changed, the derived
+// fields, the original lyrics entry and the sections are refreshed and
+// timings that no longer match are dropped.
+func saveSong(tx *gorm.DB, song *models.Song, textChanged bool) error {
+	if textChanged {
+		if err := tx.Where("song_id = ?", song.Id).Delete(&models.SyncedLine{}).Error; err != nil {
+			return err
+		}
 	}
 
-	oldSong.Song = updatedSong.Song
-	oldSong.Group = updatedSong.Group
-	oldSong.Text = updatedSong.Text
-	oldSong.ReleaseDate = updatedSong.ReleaseDate
-	oldSong.Link = updatedSong.Link
+	return saveText(tx, song, textChanged)
+}
+
+// saveText is saveSong for text whose synced lines still match it, as
+// after normalizing both.
+func saveText(tx *gorm.DB, song *models.Song, textChanged bool) error {
+	if textChanged {
+		analyzeSong(song)
+	}
 
-	if result := r.database.Save(&oldSong); result.Error != nil {
-		logger.Logger.Info().Interface("Error occured: ", result.Error).Msg(op)
+	if result := tx.Save(song); result.Error != nil {
 		return result.Error
 	}
 
+	if !textChanged {
+		return nil
+	}
+
+	if err := saveOriginal(tx, *song); err != nil {
+		return err
+	}
+
+	return saveSections(tx, song.Id, song.Text)
+}
+
+// SetExplicitOverride lets an editor force the explicit flag of a song.
+// A nil override hands the decision back to the analyzer.
+func (r *songRepo) SetExplicitOverride(ctx context.Context, id string, override *bool) error {
+	const op = "storage.repos.SetExplicitOverride"
+
+	songId, err := strconv.Atoi(id)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song models.Song
+		if result := tx.Where("id = ?", songId).First(&song); result.Error != nil {
+			return result.Error
+		}
+
+		before := song
+		song.ExplicitOverride = override
+		flagExplicit(&song)
+
+		result := tx.Model(&song).Select("explicit", "explicit_lines", "explicit_override").Updates(&song)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return writeAudit(ctx, tx, models.AuditUpdate, models.AuditSong, song.Id, before, song)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
 	return nil
 }
 
-func (r *songRepo) AddSong(newSong models.Song) (uint, error) {
-	const op = "storage.repos.AddSong"
+// FlagExplicit re-runs the explicit content analyzer over all stored
+// songs, for example after the word lists changed.
+func (r *songRepo) FlagExplicit(ctx context.Context) (int, error) {
+	const op = "storage.repos.FlagExplicit"
 
-	var group models.Group
-	result := r.database.Where("name = ?", newSong.Band).First(&group)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			logger.Logger.Info().Str("Group not found", "Creating...").Msg(op)
+	processed := 0
+	var songs []models.Song
+	result := r.database.WithContext(ctx).Model(&models.Song{}).FindInBatches(&songs, 100, func(tx *gorm.DB, batch int) error {
+		return r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, song := range songs {
+				before := song
+				flagExplicit(&song)
 
-			group = models.Group{Name: newSong.Band}
-			result := r.database.Create(&group)
-			if result.Error != nil {
-				logger.Logger.Error().Interface("Error: ", result.Error).Msg(op)
-				return 0, result.Error
+				result := tx.Model(&song).Select("explicit", "explicit_lines").Updates(&song)
+				if result.Error != nil {
+					return result.Error
+				}
+
+				if song.Explicit == before.Explicit {
+					continue
+				}
+				if err := writeAudit(ctx, tx, models.AuditUpdate, models.AuditSong, song.Id, before, song); err != nil {
+					return err
+				}
 			}
-		} else {
-			logger.Logger.Error().Interface("Error: ", result.Error).Msg(op)
-			return 0, result.Error
-		}
+
+			processed += len(songs)
+			logger.Ctx(ctx).Info().Int("processed", processed).Msg(op)
+
+			return nil
+		})
+	})
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return processed, result.Error
 	}
 
-	var maxId uint
-	r.database.Model(&models.Song{}).
-		Select("MAX(id)").Scan(&maxId)
+	return processed, nil
+}
+
+// NormalizeTexts runs the lyrics normalization pipeline over stored songs
+// that were written before it existed and returns how many of them it
+// changed. With dryRun nothing is written.
+func (r *songRepo) NormalizeTexts(ctx context.Context, dryRun bool) (int, error) {
+	const op = "storage.repos.NormalizeTexts"
+
+	changed := 0
+	var songs []models.Song
+	result := r.database.WithContext(ctx).Model(&models.Song{}).FindInBatches(&songs, 100, func(tx *gorm.DB, batch int) error {
+		return r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, song := range songs {
+				text := textnorm.Default.Apply(song.Text)
+				if text == song.Text {
+					continue
+				}
+
+				changed++
+				logger.Ctx(ctx).Info().Uint("song", song.Id).Bool("dry run", dryRun).Msg(op)
+				if dryRun {
+					continue
+				}
 
-	newSong.Id = maxId + 1
-	newSong.GroupId = group.Id
+				before := song
+				song.Text = text
+				if err := normalizeSynced(tx, song.Id); err != nil {
+					return err
+				}
+				if err := saveText(tx, &song, true); err != nil {
+					return err
+				}
 
-	result = r.database.Create(&newSong)
+				if _, err := recordRevision(ctx, tx, song, "Normalized text"); err != nil {
+					return err
+				}
+
+				if err := writeAudit(ctx, tx, models.AuditUpdate, models.AuditSong, song.Id, before, song); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
 	if result.Error != nil {
-		logger.Logger.Info().Interface("Error occured: ", result.Error).Msg(op)
-		return 0, result.Error
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return changed, result.Error
 	}
 
-	return newSong.Id, nil
+	return changed, nil
+}
+
+// normalizeSynced normalizes the text of a song's synced lines in place,
+// keeping their timings.
+func normalizeSynced(tx *gorm.DB, songId uint) error {
+	var lines []models.SyncedLine
+	if result := tx.Where("song_id = ?", songId).Find(&lines); result.Error != nil {
+		return result.Error
+	}
+
+	for _, line := range lines {
+		text := textnorm.Default.Apply(line.Text)
+		if text == line.Text {
+			continue
+		}
+
+		if result := tx.Model(&line).Update("text", text); result.Error != nil {
+			return result.Error
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,341 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"test-case/internal/models"
+	"test-case/internal/utils/logger"
+	"test-case/internal/utils/normalize"
+	"test-case/internal/utils/paginates"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TrashRepository interface {
+	GetTrash(ctx context.Context, page string, limit string) (models.Trash, error)
+	RestoreSong(ctx context.Context, id string) error
+	RestoreGroup(ctx context.Context, id string) error
+	PurgeSong(ctx context.Context, id string) error
+	PurgeGroup(ctx context.Context, id string) error
+	Purge(ctx context.Context, before time.Time) (models.PurgeResult, error)
+}
+
+var ErrGroupNameTaken = errors.New("another group already has this name")
+
+type trashRepo struct {
+	database *gorm.DB
+}
+
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepo{database: db}
+}
+
+// GetTrash lists a page of the deleted groups and songs. The songs come
+// without their text, with the band name joined from the group, which
+// may be in the trash as well.
+func (r *trashRepo) GetTrash(ctx context.Context, page string, limit string) (models.Trash, error) {
+	const op = "storage.repos.GetTrash"
+
+	trash := models.Trash{Groups: []models.Group{}, Songs: []models.Song{}}
+
+	result := r.database.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").
+		Order("deleted_at desc, id desc").Scopes(paginates.SongPaginate(page, limit)).Find(&trash.Groups)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return trash, result.Error
+	}
+
+	result = r.database.WithContext(ctx).Unscoped().
+		Select("songs.id", "songs.song", "songs.release_date", "songs.link", "songs.group_id", "songs.deleted_at").
+		Joins("Group").Where("songs.deleted_at IS NOT NULL").
+		Order("songs.deleted_at desc, songs.id desc").Scopes(paginates.SongPaginate(page, limit)).Find(&trash.Songs)
+	if result.Error != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", result.Error).Msg(op)
+		return trash, result.Error
+	}
+
+	for i := range trash.Songs {
+		trash.Songs[i].Band = trash.Songs[i].Group.Name
+	}
+
+	return trash, nil
+}
+
+// RestoreSong takes a song out of the trash, along with its group when
+// the group was deleted too.
+func (r *trashRepo) RestoreSong(ctx context.Context, id string) error {
+	const op = "storage.repos.RestoreSong"
+
+	songId, err := strconv.Atoi(id)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song models.Song
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", songId).First(&song)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var group models.Group
+		if result := tx.Unscoped().Where("id = ?", song.GroupId).First(&group); result.Error != nil {
+			return result.Error
+		}
+
+		if group.DeletedAt.Valid {
+			if err := restoreGroup(ctx, tx, group); err != nil {
+				return err
+			}
+		}
+
+		if err := lockGroup(tx, group.Id); err != nil {
+			return err
+		}
+
+		var titles []string
+		result = tx.Model(&models.Song{}).Where("group_id = ?", song.GroupId).Pluck("song", &titles)
+		if result.Error != nil {
+			return result.Error
+		}
+		for _, title := range titles {
+			if normalize.TitleKey(title) == normalize.TitleKey(song.Song) {
+				return ErrDuplicateSong
+			}
+		}
+
+		return restoreSongs(ctx, tx, []models.Song{song})
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	return nil
+}
+
+// RestoreGroup takes a group out of the trash together with the songs
+// that were deleted with it.
+func (r *trashRepo) RestoreGroup(ctx context.Context, id string) error {
+	const op = "storage.repos.RestoreGroup"
+
+	groupId, err := strconv.Atoi(id)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", groupId).First(&group)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var songs []models.Song
+		result = tx.Unscoped().Where("group_id = ? AND deleted_at = ?", group.Id, group.DeletedAt).Find(&songs)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := restoreGroup(ctx, tx, group); err != nil {
+			return err
+		}
+
+		return restoreSongs(ctx, tx, songs)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	return nil
+}
+
+func restoreGroup(ctx context.Context, tx *gorm.DB, group models.Group) error {
+	var taken int64
+	result := tx.Model(&models.Group{}).Where("name_key = ?", group.NameKey).Count(&taken)
+	if result.Error != nil {
+		return result.Error
+	}
+	if taken > 0 {
+		return ErrGroupNameTaken
+	}
+
+	result = tx.Unscoped().Model(&models.Group{}).Where("id = ?", group.Id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	before := group
+	group.DeletedAt = gorm.DeletedAt{}
+	return writeAudit(ctx, tx, models.AuditRestore, models.AuditGroup, group.Id, before, group)
+}
+
+func restoreSongs(ctx context.Context, tx *gorm.DB, songs []models.Song) error {
+	for _, song := range songs {
+		result := tx.Unscoped().Model(&models.Song{}).Where("id = ?", song.Id).Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if _, err := recordRevision(ctx, tx, song, "Restored from trash"); err != nil {
+			return err
+		}
+
+		before := song
+		song.DeletedAt = gorm.DeletedAt{}
+		if err := writeAudit(ctx, tx, models.AuditRestore, models.AuditSong, song.Id, before, song); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeSong deletes a song in the trash for good, with its lyrics and
+// history.
+func (r *trashRepo) PurgeSong(ctx context.Context, id string) error {
+	const op = "storage.repos.PurgeSong"
+
+	songId, err := strconv.Atoi(id)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var songs []models.Song
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", songId).Find(&songs)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(songs) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return purgeSongs(ctx, tx, songs)
+	})
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func purgeSongs(ctx context.Context, tx *gorm.DB, songs []models.Song) error {
+	for _, song := range songs {
+		if err := tx.Unscoped().Delete(&models.Song{}, song.Id).Error; err != nil {
+			return err
+		}
+
+		if err := writeAudit(ctx, tx, models.AuditPurge, models.AuditSong, song.Id, song, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeGroup deletes a group in the trash for good, with its aliases and
+// songs.
+func (r *trashRepo) PurgeGroup(ctx context.Context, id string) error {
+	const op = "storage.repos.PurgeGroup"
+
+	groupId, err := strconv.Atoi(id)
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	err = r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.Group
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", groupId).First(&group)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return purgeGroup(ctx, tx, group)
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return err
+	}
+
+	return nil
+}
+
+func purgeGroup(ctx context.Context, tx *gorm.DB, group models.Group) error {
+	var songs []models.Song
+	if result := tx.Unscoped().Where("group_id = ?", group.Id).Find(&songs); result.Error != nil {
+		return result.Error
+	}
+
+	if err := purgeSongs(ctx, tx, songs); err != nil {
+		return err
+	}
+
+	if err := tx.Where("group_id = ?", group.Id).Delete(&models.GroupAlias{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Unscoped().Delete(&models.Group{}, group.Id).Error; err != nil {
+		return err
+	}
+
+	return writeAudit(ctx, tx, models.AuditPurge, models.AuditGroup, group.Id, group, nil)
+}
+
+// Purge deletes everything that went to the trash before the given time.
+func (r *trashRepo) Purge(ctx context.Context, before time.Time) (models.PurgeResult, error) {
+	const op = "storage.repos.Purge"
+
+	var purged models.PurgeResult
+	err := r.database.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var songs []models.Song
+		result := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Find(&songs)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := purgeSongs(ctx, tx, songs); err != nil {
+			return err
+		}
+		purged.Songs = int64(len(songs))
+
+		// A group goes only once none of its songs is left.
+		var groups []models.Group
+		result = tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Where("NOT EXISTS (?)", tx.Unscoped().Model(&models.Song{}).Select("1").Where("songs.group_id = groups.id")).
+			Find(&groups)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		for _, group := range groups {
+			if err := purgeGroup(ctx, tx, group); err != nil {
+				return err
+			}
+		}
+		purged.Groups = int64(len(groups))
+
+		return nil
+	})
+	if err != nil {
+		logger.Ctx(ctx).Info().Interface("Error occured: ", err).Msg(op)
+		return purged, err
+	}
+
+	if purged.Songs > 0 || purged.Groups > 0 {
+		logger.Ctx(ctx).Info().Int64("songs", purged.Songs).Int64("groups", purged.Groups).Msg(op)
+	}
+
+	return purged, nil
+}